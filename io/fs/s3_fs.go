@@ -7,9 +7,14 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3"
 	"io"
 	systemFS "io/fs"
+	"sort"
+	"strings"
 )
 
-var _ FS = (*s3FS)(nil)
+var (
+	_ FS = (*s3FS)(nil)
+	_ FS = (*s3DirFS)(nil)
+)
 
 type s3FS struct {
 	protocol *s3.S3
@@ -160,3 +165,94 @@ func (fs *s3FS) Delete(ctx context.Context, name string) error {
 	})
 	return err
 }
+
+// s3DirFS is a s3FS whose ReadDir lists one level of the bucket like a directory
+type s3DirFS struct {
+	*s3FS
+}
+
+// NewS3DirFS is like NewS3FS, but its ReadDir lists a directory as io/fs expects, so it works with fs.WalkDir
+// and sql-migrate's FSMigrationSource. The ReadDir of NewS3FS lists every object under the prefix by its full key.
+func NewS3DirFS(protocol *s3.S3, bucket string) FS {
+	return &s3DirFS{
+		s3FS: &s3FS{
+			protocol: protocol,
+			bucket:   bucket,
+		},
+	}
+}
+
+func (fs *s3DirFS) ReadDir(name string) (entries []systemFS.DirEntry, err error) {
+	return fs.ReadDirWithContext(context.Background(), name)
+}
+
+// ReadDirWithContext lists the objects directly under the prefix name like a directory: entries are named
+// by their key without the prefix, and the common prefixes up to the next "/" are directories.
+// The root directory is "." or "".
+func (fs *s3DirFS) ReadDirWithContext(ctx context.Context, name string) (entries []systemFS.DirEntry, err error) {
+	prefix := strings.TrimSuffix(name, "/")
+	if prefix == "." {
+		prefix = ""
+	}
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	var continuationToken *string
+	for {
+		listObjects, err := fs.protocol.ListObjectsV2WithContext(ctx, &s3.ListObjectsV2Input{
+			Bucket:            aws.String(fs.bucket),
+			ContinuationToken: continuationToken,
+			Delimiter:         aws.String("/"),
+			Prefix:            aws.String(prefix),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, commonPrefix := range listObjects.CommonPrefixes {
+			dirName := strings.TrimSuffix(strings.TrimPrefix(*commonPrefix.Prefix, prefix), "/")
+			entries = append(entries, &dirEntry{
+				name:  dirName,
+				isDir: true,
+				ftype: systemFS.ModeDir,
+				fileInfo: &fileInfo{
+					name:  dirName,
+					mode:  systemFS.ModeDir | 0700,
+					isDir: true,
+					sys:   commonPrefix,
+				},
+			})
+		}
+		for _, obj := range listObjects.Contents {
+			fileName := strings.TrimPrefix(*obj.Key, prefix)
+			// the placeholder object of the directory itself
+			if fileName == "" {
+				continue
+			}
+			entries = append(entries, &dirEntry{
+				name:  fileName,
+				isDir: false,
+				ftype: 0600,
+				fileInfo: &fileInfo{
+					name:    fileName,
+					size:    *obj.Size,
+					mode:    0600,
+					modTime: *obj.LastModified,
+					isDir:   false,
+					sys:     obj,
+				},
+			})
+		}
+
+		if *listObjects.IsTruncated == false {
+			break
+		}
+		continuationToken = listObjects.NextContinuationToken
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/hawkneo/utils/io/fs/s3test"
 	"github.com/stretchr/testify/require"
+	systemFS "io/fs"
 	"os"
 	"strings"
 	"testing"
@@ -56,3 +58,75 @@ func TestNewS3FS(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, text, buf.String())
 }
+
+func TestS3FS_ReadDir(t *testing.T) {
+	server := s3test.NewServer("bucket", map[string][]byte{
+		"migrations/V1__a.sql":      []byte("a"),
+		"migrations/2023/V2__b.sql": []byte("b"),
+		"README.md":                 []byte("readme"),
+	})
+	defer server.Close()
+	s3FS := NewS3FS(server.Client(), "bucket")
+
+	// every object under the prefix, by its full key
+	names := func(entries []systemFS.DirEntry) (names []string) {
+		for _, entry := range entries {
+			require.False(t, entry.IsDir())
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+	entries, err := s3FS.ReadDir("migrations")
+	require.NoError(t, err)
+	require.Equal(t, []string{"migrations/2023/V2__b.sql", "migrations/V1__a.sql"}, names(entries))
+
+	entries, err = s3FS.ReadDir("")
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+}
+
+func TestS3DirFS_ReadDir(t *testing.T) {
+	server := s3test.NewServer("bucket", map[string][]byte{
+		"migrations/":                  nil,
+		"migrations/V1__a.sql":         []byte("a"),
+		"migrations/2023/V2__b.sql":    []byte("b"),
+		"migrations/2023/06/V3__c.sql": []byte("c"),
+		"migrations_old/V0__old.sql":   []byte("old"),
+		"README.md":                    []byte("readme"),
+	})
+	defer server.Close()
+	s3FS := NewS3DirFS(server.Client(), "bucket")
+
+	names := func(entries []systemFS.DirEntry) (names []string) {
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() {
+				name += "/"
+			}
+			names = append(names, name)
+		}
+		return names
+	}
+
+	entries, err := s3FS.ReadDir("migrations")
+	require.NoError(t, err)
+	require.Equal(t, []string{"2023/", "V1__a.sql"}, names(entries))
+	info, err := entries[1].Info()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), info.Size())
+	require.Equal(t, s3test.ModTime, info.ModTime())
+
+	entries, err = s3FS.ReadDir("migrations/2023/")
+	require.NoError(t, err)
+	require.Equal(t, []string{"06/", "V2__b.sql"}, names(entries))
+
+	for _, root := range []string{"", "."} {
+		entries, err = s3FS.ReadDir(root)
+		require.NoError(t, err)
+		require.Equal(t, []string{"README.md", "migrations/", "migrations_old/"}, names(entries))
+	}
+
+	bz, err := systemFS.ReadFile(s3FS, "migrations/2023/V2__b.sql")
+	require.NoError(t, err)
+	require.Equal(t, "b", string(bz))
+}
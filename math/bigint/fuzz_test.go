@@ -0,0 +1,134 @@
+package bigint_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/hawkneo/utils/math"
+	"github.com/hawkneo/utils/math/bigint"
+	"github.com/hawkneo/utils/math/decimal/decimaltest"
+)
+
+// quoRoundingModes are the rounding modes supported by BigInt.Quo.
+var quoRoundingModes = []math.RoundingMode{
+	math.RoundDown,
+	math.RoundUp,
+	math.RoundCeiling,
+	math.RoundUnnecessary,
+}
+
+func FuzzBigInt_Quo(f *testing.F) {
+	f.Add(int64(5), int64(2), uint8(0))
+	f.Add(int64(-5), int64(2), uint8(1))
+	f.Add(int64(5), int64(-2), uint8(2))
+	f.Add(int64(-5), int64(-2), uint8(2))
+	f.Add(int64(6), int64(-3), uint8(3))
+	f.Add(int64(7), int64(-3), uint8(3))
+	f.Fuzz(func(t *testing.T, a, b int64, m uint8) {
+		if b == 0 {
+			t.Skip("division by zero")
+		}
+		mode := quoRoundingModes[int(m)%len(quoRoundingModes)]
+		want, ok := decimaltest.Round(big.NewRat(a, b), 0, mode)
+
+		var got bigint.BigInt
+		panicked := func() (panicked bool) {
+			defer func() { panicked = recover() != nil }()
+			got = bigint.NewFromInt64(a).Quo(bigint.NewFromInt64(b), mode)
+			return
+		}()
+		if !ok {
+			if !panicked {
+				t.Fatalf("%d / %d (mode %d) = %s, want panic", a, b, mode, got)
+			}
+			return
+		}
+		if panicked {
+			t.Fatalf("%d / %d (mode %d) panicked, want %s", a, b, mode, want)
+		}
+		if got.BigInt().Cmp(want) != 0 {
+			t.Fatalf("%d / %d (mode %d) = %s, want %s", a, b, mode, got, want)
+		}
+	})
+}
+
+// FuzzBigInt_Arithmetic checks the operations which wrap math/big against math/big itself,
+// int64 operands keep the expected values exact
+func FuzzBigInt_Arithmetic(f *testing.F) {
+	f.Add(int64(5), int64(2), uint8(3))
+	f.Add(int64(-5), int64(2), uint8(0))
+	f.Add(int64(5), int64(-7), uint8(64))
+	f.Add(int64(0), int64(0), uint8(1))
+	f.Add(int64(1<<62), int64(-1<<63), uint8(255))
+	f.Fuzz(func(t *testing.T, a, b int64, n uint8) {
+		x, y := big.NewInt(a), big.NewInt(b)
+		bx, by := bigint.NewFromInt64(a), bigint.NewFromInt64(b)
+		// keeps the powers small
+		power := int64(n % 8)
+
+		checkBigInt(t, "add", bx.Add(by), new(big.Int).Add(x, y))
+		checkBigInt(t, "sub", bx.Sub(by), new(big.Int).Sub(x, y))
+		checkBigInt(t, "mul", bx.Mul(by), new(big.Int).Mul(x, y))
+		checkBigInt(t, "neg", bx.Neg(), new(big.Int).Neg(x))
+		checkBigInt(t, "abs", bx.Abs(), new(big.Int).Abs(x))
+		checkBigInt(t, "power", bx.Power(power), new(big.Int).Exp(x, big.NewInt(power), nil))
+		checkBigInt(t, "shift left", bx.ShiftLeft(uint(n)), new(big.Int).Lsh(x, uint(n)))
+		checkBigInt(t, "shift right", bx.ShiftRight(uint(n)), new(big.Int).Rsh(x, uint(n)))
+		if b != 0 {
+			checkBigInt(t, "mod", bx.Mod(by), new(big.Int).Mod(x, y))
+			checkBigInt(t, "quo down", bx.QuoDown(by), new(big.Int).Quo(x, y))
+		}
+		if a >= 0 {
+			checkBigInt(t, "sqrt", bx.Sqrt(), new(big.Int).Sqrt(x))
+		}
+		if bx.Sign() != x.Sign() || bx.BitLen() != x.BitLen() || bx.GetInt64() != a {
+			t.Fatalf("%d: sign %d, bit len %d, int64 %d", a, bx.Sign(), bx.BitLen(), bx.GetInt64())
+		}
+		parsed, ok := bigint.NewFromString(bx.String())
+		if !ok || !parsed.Equal(bx) {
+			t.Fatalf("NewFromString(%q) = %s, %v", bx.String(), parsed, ok)
+		}
+	})
+}
+
+func FuzzBigInt_Cmp(f *testing.F) {
+	f.Add(int64(5), int64(2))
+	f.Add(int64(-5), int64(-5))
+	f.Add(int64(-1<<63), int64(1<<62))
+	f.Fuzz(func(t *testing.T, a, b int64) {
+		bx, by := bigint.NewFromInt64(a), bigint.NewFromInt64(b)
+		want := big.NewInt(a).Cmp(big.NewInt(b))
+		if got := bx.Cmp(by); got != want {
+			t.Fatalf("cmp(%d, %d) = %d, want %d", a, b, got, want)
+		}
+		if bx.Equal(by) != (want == 0) || bx.GT(by) != (want > 0) || bx.GTE(by) != (want >= 0) ||
+			bx.LT(by) != (want < 0) || bx.LTE(by) != (want <= 0) {
+			t.Fatalf("comparisons of %d and %d do not match cmp %d", a, b, want)
+		}
+		max, min := bigint.Max(bx, by), bigint.Min(bx, by)
+		if want >= 0 && (!max.Equal(bx) || !min.Equal(by)) || want < 0 && (!max.Equal(by) || !min.Equal(bx)) {
+			t.Fatalf("max(%d, %d) = %s, min = %s", a, b, max, min)
+		}
+	})
+}
+
+func checkBigInt(t *testing.T, op string, got bigint.BigInt, want *big.Int) {
+	t.Helper()
+	if got.BigInt().Cmp(want) != 0 {
+		t.Fatalf("%s = %s, want %s", op, got, want)
+	}
+}
+
+func FuzzBigInt_Marshal(f *testing.F) {
+	f.Add(int64(0))
+	f.Add(int64(-1))
+	f.Add(int64(1 << 62))
+	f.Add(int64(-1 << 63))
+	f.Fuzz(func(t *testing.T, a int64) {
+		b := bigint.NewFromInt64(a)
+		decimaltest.CheckJSON(t, b, bigint.BigInt.Equal)
+		decimaltest.CheckBinary(t, b, bigint.BigInt.Equal)
+		decimaltest.CheckSQL(t, b, bigint.BigInt.Equal)
+		decimaltest.CheckGogo(t, b, bigint.BigInt.Equal)
+	})
+}
@@ -374,9 +374,9 @@ func (d Decimal) Quo(d2 Decimal, roundingMode math.RoundingMode) Decimal {
 		d1Twice = new(big.Int).Mul(d1Twice, precisionMultipliers[1])
 
 		return Decimal{
-			i:    new(big.Int).Quo(d1Twice, d2.i),
-			prec: 1 * 2,
-		}.Rescale(0, roundingMode)
+			i:    roundQuotient(d1Twice, d2.i, 1*2, roundingMode),
+			prec: 0,
+		}
 	}
 
 	d1, d2, maxPrec := rescalePair(d, d2)
@@ -385,9 +385,27 @@ func (d Decimal) Quo(d2 Decimal, roundingMode math.RoundingMode) Decimal {
 	d1Twice = new(big.Int).Mul(d1Twice, precisionMultipliers[maxPrec])
 
 	return Decimal{
-		i:    new(big.Int).Quo(d1Twice, d2.i),
+		i:    roundQuotient(d1Twice, d2.i, maxPrec, roundingMode),
 		prec: maxPrec,
-	}.round(roundingMode)
+	}
+}
+
+// roundQuotient returns x / y with prec digits rounded off, prec must be greater than 0.
+// The truncated quotient hides the remainder from round, so an inexact quotient
+// that lands on a rounding boundary is moved one unit away from zero first.
+func roundQuotient(x, y *big.Int, prec int, roundingMode math.RoundingMode) *big.Int {
+	quo, rem := new(big.Int).QuoRem(x, y, new(big.Int))
+	if rem.Sign() != 0 {
+		dropped := new(big.Int).Rem(new(big.Int).Abs(quo), precisionMultipliers[prec])
+		half := new(big.Int).Mul(fiveInt, precisionMultipliers[prec-1])
+		if dropped.Sign() == 0 || dropped.Cmp(half) == 0 {
+			quo.Add(quo, big.NewInt(int64(x.Sign()*y.Sign())))
+		}
+	}
+	return Decimal{
+		i:    quo,
+		prec: prec,
+	}.round(roundingMode).i
 }
 
 func (d Decimal) QuoDown(d2 Decimal) Decimal {
@@ -587,7 +605,8 @@ func (d Decimal) StripTrailingZeros() Decimal {
 	return MustFromString(fmt.Sprintf("%s.%s", splits[0], splits[1]))
 }
 
-// SignificantFigures returns a Decimal with the specified number of significant figures
+// SignificantFigures returns a Decimal with the specified number of significant figures,
+// a Decimal whose precision is not greater than figures is returned unchanged
 func (d Decimal) SignificantFigures(figures int, roundingMode math.RoundingMode) Decimal {
 	if figures <= 0 {
 		panic("figures must be greater than 0")
@@ -402,6 +402,65 @@ func TestDecimal_QuoRoundUp(t *testing.T) {
 	}
 }
 
+// TestDecimal_QuoRounding covers quotients whose digits beyond the result precision are all zeros, or
+// exactly half, once truncated, with and without a remainder
+func TestDecimal_QuoRounding(t *testing.T) {
+	tests := []struct {
+		value1       Decimal
+		value2       Decimal
+		roundingMode math.RoundingMode
+		want         string // empty if the rounding panics
+	}{
+		// 0.10101..., truncated to 0.10
+		{MustFromString("1.0"), MustFromString("9.9"), math.RoundUp, "0.2"},
+		{MustFromString("1.0"), MustFromString("9.9"), math.RoundCeiling, "0.2"},
+		{MustFromString("1.0"), MustFromString("9.9"), math.RoundDown, "0.1"},
+		{MustFromString("1.0"), MustFromString("9.9"), math.RoundHalfUp, "0.1"},
+		{MustFromString("1.0"), MustFromString("9.9"), math.RoundUnnecessary, ""},
+		{MustFromString("-1.0"), MustFromString("9.9"), math.RoundUp, "-0.2"},
+		{MustFromString("-1.0"), MustFromString("9.9"), math.RoundCeiling, "-0.1"},
+		{MustFromString("10"), MustFromString("99"), math.RoundUp, "1"},
+
+		// 0.15625 and 0.25641..., truncated to 0.15 and 0.25
+		{MustFromString("1.0"), MustFromString("6.4"), math.RoundHalfDown, "0.2"},
+		{MustFromString("1.0"), MustFromString("6.4"), math.RoundHalfEven, "0.2"},
+		{MustFromString("1.0"), MustFromString("3.9"), math.RoundHalfEven, "0.3"},
+		{MustFromString("1.0"), MustFromString("3.9"), math.RoundHalfDown, "0.3"},
+		{MustFromString("-1.0"), MustFromString("3.9"), math.RoundHalfEven, "-0.3"},
+		{MustFromString("1001"), MustFromString("2000"), math.RoundHalfDown, "1"},
+
+		// exact quotients
+		{MustFromString("1.0"), MustFromString("4.0"), math.RoundHalfEven, "0.2"},
+		{MustFromString("1.0"), MustFromString("4.0"), math.RoundHalfDown, "0.2"},
+		{MustFromString("1.0"), MustFromString("4.0"), math.RoundHalfUp, "0.3"},
+		{MustFromString("1.0"), MustFromString("4.0"), math.RoundUnnecessary, ""},
+		{MustFromString("-1.0"), MustFromString("4.0"), math.RoundHalfUp, "-0.3"},
+		{MustFromString("1.0"), MustFromString("8.0"), math.RoundHalfUp, "0.1"},
+		{MustFromString("1.0"), MustFromString("8.0"), math.RoundUp, "0.2"},
+		{MustFromString("1.0"), MustFromString("2.0"), math.RoundUnnecessary, "0.5"},
+		{MustFromString("1"), MustFromString("2"), math.RoundHalfDown, "0"},
+		{MustFromString("5"), MustFromString("2"), math.RoundHalfEven, "2"},
+		{MustFromString("3"), MustFromString("2"), math.RoundHalfEven, "2"},
+	}
+	for _, test := range tests {
+		name := fmt.Sprintf("%s/%s (mode %d)", test.value1, test.value2, test.roundingMode)
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != nil && test.want != "" {
+					t.Fatalf("expected %s, got panic %v", test.want, r)
+				}
+			}()
+			val := test.value1.Quo(test.value2, test.roundingMode)
+			if test.want == "" {
+				t.Fatalf("expected panic, got %s", val)
+			}
+			if val.String() != test.want {
+				t.Fatalf("expected %s, got %s", test.want, val)
+			}
+		})
+	}
+}
+
 func TestDecimal_Power(t *testing.T) {
 	tests := []struct {
 		value         Decimal
@@ -723,6 +782,11 @@ func TestDecimal_SignificantFigures(t *testing.T) {
 		{MustFromString("-1111.001001"), 3, math.RoundUp, "-1112", "-1111.001001"},
 		{MustFromString("-1111.001001"), 4, math.RoundUp, "-1112", "-1111.001001"},
 		{MustFromString("-1111.001001"), 5, math.RoundUp, "-1111.1", "-1111.001001"},
+
+		// a precision not greater than figures is kept
+		{MustFromString("123.45"), 3, math.RoundUp, "123.45", "123.45"},
+		{MustFromString("123.456"), 3, math.RoundUp, "123.456", "123.456"},
+		{MustFromString("123.4567"), 3, math.RoundUp, "124", "123.4567"},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -0,0 +1,224 @@
+// Package decimaltest provides a big.Rat based oracle and reusable checks for
+// decimal.Decimal and other fixed-point types built on top of it.
+package decimaltest
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/hawkneo/utils/math"
+	"github.com/hawkneo/utils/math/decimal"
+)
+
+// RoundingModes contains every supported math.RoundingMode.
+var RoundingModes = []math.RoundingMode{
+	math.RoundDown,
+	math.RoundUp,
+	math.RoundCeiling,
+	math.RoundHalfUp,
+	math.RoundHalfDown,
+	math.RoundHalfEven,
+	math.RoundUnnecessary,
+}
+
+// RoundingMode maps an arbitrary fuzz input to one of RoundingModes.
+func RoundingMode(m uint8) math.RoundingMode {
+	return RoundingModes[int(m)%len(RoundingModes)]
+}
+
+// Decimal builds a Decimal from fuzz inputs, folding prec into [0, maxPrec].
+func Decimal(value int64, prec uint8, maxPrec int) decimal.Decimal {
+	return decimal.NewFromInt64(value, int(prec)%(maxPrec+1))
+}
+
+// Rat returns the exact value of d.
+func Rat(d decimal.Decimal) *big.Rat {
+	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.Precision())), nil)
+	return new(big.Rat).SetFrac(d.BigInt(), denom)
+}
+
+// Round rounds r to prec fractional digits and returns the unscaled integer,
+// i.e. the value of Decimal.BigInt for a Decimal with precision prec.
+//
+// ok is false if mode is math.RoundUnnecessary and r can not be represented
+// exactly with prec fractional digits.
+func Round(r *big.Rat, prec int, mode math.RoundingMode) (i *big.Int, ok bool) {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(prec)), nil)
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(scale))
+
+	// QuoRem truncates towards zero, so quo is already the RoundDown result
+	quo, rem := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if rem.Sign() == 0 {
+		return quo, true
+	}
+
+	twiceRem := new(big.Int).Lsh(new(big.Int).Abs(rem), 1)
+	half := twiceRem.Cmp(scaled.Denom())
+
+	var away bool
+	switch mode {
+	case math.RoundDown:
+		away = false
+	case math.RoundUp:
+		away = true
+	case math.RoundCeiling:
+		away = r.Sign() > 0
+	case math.RoundHalfUp:
+		away = half >= 0
+	case math.RoundHalfDown:
+		away = half > 0
+	case math.RoundHalfEven:
+		away = half > 0 || (half == 0 && new(big.Int).Abs(quo).Bit(0) == 1)
+	case math.RoundUnnecessary:
+		return nil, false
+	default:
+		panic("invalid rounding mode")
+	}
+
+	if away {
+		quo.Add(quo, big.NewInt(int64(r.Sign())))
+	}
+	return quo, true
+}
+
+// CheckAdd asserts that a.Add(b) is exact.
+func CheckAdd(t testing.TB, a, b decimal.Decimal) {
+	t.Helper()
+	prec := maxInt(a.Precision(), b.Precision())
+	want, ok := Round(new(big.Rat).Add(Rat(a), Rat(b)), prec, math.RoundUnnecessary)
+	got, panicked := try(func() decimal.Decimal { return a.Add(b) })
+	check(t, fmt.Sprintf("%s + %s", a, b), prec, want, ok, got, panicked)
+}
+
+// CheckSub asserts that a.Sub(b) is exact.
+func CheckSub(t testing.TB, a, b decimal.Decimal) {
+	t.Helper()
+	prec := maxInt(a.Precision(), b.Precision())
+	want, ok := Round(new(big.Rat).Sub(Rat(a), Rat(b)), prec, math.RoundUnnecessary)
+	got, panicked := try(func() decimal.Decimal { return a.Sub(b) })
+	check(t, fmt.Sprintf("%s - %s", a, b), prec, want, ok, got, panicked)
+}
+
+// CheckMul asserts that a.Mul(b, mode) equals the exact product rounded to
+// the larger precision of a and b.
+func CheckMul(t testing.TB, a, b decimal.Decimal, mode math.RoundingMode) {
+	t.Helper()
+	prec := maxInt(a.Precision(), b.Precision())
+	want, ok := Round(new(big.Rat).Mul(Rat(a), Rat(b)), prec, mode)
+	got, panicked := try(func() decimal.Decimal { return a.Mul(b, mode) })
+	check(t, fmt.Sprintf("%s * %s (mode %d)", a, b, mode), prec, want, ok, got, panicked)
+}
+
+// CheckQuo asserts that a.Quo(b, mode) equals the exact quotient rounded to
+// the larger precision of a and b. b must not be zero.
+func CheckQuo(t testing.TB, a, b decimal.Decimal, mode math.RoundingMode) {
+	t.Helper()
+	prec := maxInt(a.Precision(), b.Precision())
+	want, ok := Round(new(big.Rat).Quo(Rat(a), Rat(b)), prec, mode)
+	got, panicked := try(func() decimal.Decimal { return a.Quo(b, mode) })
+	check(t, fmt.Sprintf("%s / %s (mode %d)", a, b, mode), prec, want, ok, got, panicked)
+}
+
+// CheckRescale asserts that d.Rescale(prec, mode) equals d rounded to prec
+// fractional digits.
+func CheckRescale(t testing.TB, d decimal.Decimal, prec int, mode math.RoundingMode) {
+	t.Helper()
+	want, ok := Round(Rat(d), prec, mode)
+	if d.Precision() == prec {
+		// Rescale is a no-op, even for math.RoundUnnecessary
+		want, ok = d.BigInt(), true
+	}
+	got, panicked := try(func() decimal.Decimal { return d.Rescale(prec, mode) })
+	check(t, fmt.Sprintf("rescale(%s, %d) (mode %d)", d, prec, mode), prec, want, ok, got, panicked)
+}
+
+// CheckSignificantFigures asserts that d.SignificantFigures(figures, mode)
+// keeps figures significant digits in the fractional part of d, unless the
+// precision of d is not greater than figures.
+func CheckSignificantFigures(t testing.TB, d decimal.Decimal, figures int, mode math.RoundingMode) {
+	t.Helper()
+	prec := significantPrecision(Rat(d), d.Precision(), figures)
+	want, ok := Round(Rat(d), prec, mode)
+	got, panicked := try(func() decimal.Decimal { return d.SignificantFigures(figures, mode) })
+	check(t, fmt.Sprintf("significantFigures(%s, %d) (mode %d)", d, figures, mode), prec, want, ok, got, panicked)
+}
+
+// CheckString asserts that d survives a String/NewFromString round trip with
+// its precision unchanged.
+func CheckString(t testing.TB, d decimal.Decimal) {
+	t.Helper()
+	parsed, err := decimal.NewFromString(d.String())
+	if err != nil {
+		t.Fatalf("NewFromString(%q): %v", d.String(), err)
+	}
+	if !Identical(d, parsed) {
+		t.Fatalf("NewFromString(%q) = %s (prec %d), want prec %d", d.String(), parsed, parsed.Precision(), d.Precision())
+	}
+}
+
+// Identical reports whether a and b have the same value and precision.
+func Identical(a, b decimal.Decimal) bool {
+	if a.IsNil() || b.IsNil() {
+		return a.IsNil() == b.IsNil()
+	}
+	return a.Precision() == b.Precision() && a.BigInt().Cmp(b.BigInt()) == 0
+}
+
+// significantPrecision returns the precision SignificantFigures rounds to:
+// a precision not greater than figures is kept, integer digits consume figures
+// first, and leading fractional zeros of values below one do not count.
+func significantPrecision(r *big.Rat, prec, figures int) int {
+	if prec <= figures || r.Sign() == 0 {
+		return prec
+	}
+	abs := new(big.Rat).Abs(r)
+	one := big.NewRat(1, 1)
+	keep := figures
+	if abs.Cmp(one) >= 0 {
+		intPart := new(big.Int).Quo(abs.Num(), abs.Denom())
+		keep = maxInt(figures-len(intPart.String()), 0)
+	} else {
+		ten := big.NewRat(10, 1)
+		for shifted := new(big.Rat).Mul(abs, ten); shifted.Cmp(one) < 0; shifted.Mul(shifted, ten) {
+			keep++
+		}
+	}
+	if keep > prec {
+		return prec
+	}
+	return keep
+}
+
+func check(t testing.TB, op string, prec int, want *big.Int, ok bool, got decimal.Decimal, panicked bool) {
+	t.Helper()
+	if !ok {
+		if !panicked {
+			t.Fatalf("%s = %s, want panic", op, got)
+		}
+		return
+	}
+	if panicked {
+		t.Fatalf("%s panicked, want %s", op, decimal.NewFromBigIntWithPrec(want, prec))
+	}
+	if got.Precision() != prec || got.BigInt().Cmp(want) != 0 {
+		t.Fatalf("%s = %s (prec %d), want %s (prec %d)",
+			op, got, got.Precision(), decimal.NewFromBigIntWithPrec(want, prec), prec)
+	}
+}
+
+func try(fn func() decimal.Decimal) (d decimal.Decimal, panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+		}
+	}()
+	return fn(), false
+}
+
+func maxInt(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
@@ -0,0 +1,125 @@
+package decimaltest
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"testing"
+
+	"github.com/hawkneo/utils/math/decimal"
+)
+
+// GogoMarshaler is the marshal half of the gogo proto custom type interface.
+type GogoMarshaler interface {
+	Marshal() ([]byte, error)
+	MarshalTo(data []byte) (n int, err error)
+	Size() int
+}
+
+// CheckJSON asserts that v survives a json.Marshal/json.Unmarshal round trip.
+func CheckJSON[T any, P interface {
+	*T
+	json.Unmarshaler
+}](t testing.TB, v T, equal func(a, b T) bool) {
+	t.Helper()
+	bz, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal(%v): %v", v, err)
+	}
+	var got T
+	if err := json.Unmarshal(bz, P(&got)); err != nil {
+		t.Fatalf("json.Unmarshal(%s): %v", bz, err)
+	}
+	if !equal(v, got) {
+		t.Fatalf("json round trip of %v via %s = %v", v, bz, got)
+	}
+}
+
+// CheckBinary asserts that v survives a MarshalBinary/UnmarshalBinary round trip.
+func CheckBinary[T encoding.BinaryMarshaler, P interface {
+	*T
+	encoding.BinaryUnmarshaler
+}](t testing.TB, v T, equal func(a, b T) bool) {
+	t.Helper()
+	bz, err := v.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary(%v): %v", v, err)
+	}
+	var got T
+	if err := P(&got).UnmarshalBinary(bz); err != nil {
+		t.Fatalf("UnmarshalBinary(%x): %v", bz, err)
+	}
+	if !equal(v, got) {
+		t.Fatalf("binary round trip of %v via %x = %v", v, bz, got)
+	}
+}
+
+// CheckSQL asserts that v survives a Value/Scan round trip, both when the
+// driver hands back the value as is and when it hands it back as bytes.
+func CheckSQL[T driver.Valuer, P interface {
+	*T
+	sql.Scanner
+}](t testing.TB, v T, equal func(a, b T) bool) {
+	t.Helper()
+	value, err := v.Value()
+	if err != nil {
+		t.Fatalf("Value(%v): %v", v, err)
+	}
+	values := []any{value}
+	if s, ok := value.(string); ok {
+		values = append(values, []byte(s))
+	}
+	for _, value := range values {
+		var got T
+		if err := P(&got).Scan(value); err != nil {
+			t.Fatalf("Scan(%#v): %v", value, err)
+		}
+		if !equal(v, got) {
+			t.Fatalf("sql round trip of %v via %#v = %v", v, value, got)
+		}
+	}
+}
+
+// CheckGogo asserts that v survives a gogo proto Marshal/Unmarshal round trip,
+// and that Size and MarshalTo agree with Marshal.
+func CheckGogo[T GogoMarshaler, P interface {
+	*T
+	Unmarshal(data []byte) error
+}](t testing.TB, v T, equal func(a, b T) bool) {
+	t.Helper()
+	bz, err := v.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal(%v): %v", v, err)
+	}
+	if v.Size() != len(bz) {
+		t.Fatalf("Size(%v) = %d, want %d", v, v.Size(), len(bz))
+	}
+	buf := make([]byte, v.Size())
+	n, err := v.MarshalTo(buf)
+	if err != nil {
+		t.Fatalf("MarshalTo(%v): %v", v, err)
+	}
+	if !bytes.Equal(buf[:n], bz) {
+		t.Fatalf("MarshalTo(%v) = %x, want %x", v, buf[:n], bz)
+	}
+	var got T
+	if err := P(&got).Unmarshal(bz); err != nil {
+		t.Fatalf("Unmarshal(%x): %v", bz, err)
+	}
+	if !equal(v, got) {
+		t.Fatalf("gogo round trip of %v via %x = %v", v, bz, got)
+	}
+}
+
+// CheckMarshal runs every round trip check on d, requiring the precision to be
+// preserved.
+func CheckMarshal(t testing.TB, d decimal.Decimal) {
+	t.Helper()
+	CheckString(t, d)
+	CheckJSON(t, d, Identical)
+	CheckBinary(t, d, Identical)
+	CheckSQL(t, d, Identical)
+	CheckGogo(t, d, Identical)
+}
@@ -0,0 +1,120 @@
+package decimal_test
+
+import (
+	"testing"
+
+	"github.com/hawkneo/utils/math"
+	"github.com/hawkneo/utils/math/decimal"
+	"github.com/hawkneo/utils/math/decimal/decimaltest"
+)
+
+// maxFuzzPrecision keeps the fuzzed precision low enough that int64 values
+// still produce integer parts.
+const maxFuzzPrecision = 24
+
+func addBinarySeeds(f *testing.F) {
+	f.Add(int64(5), uint8(0), int64(2), uint8(0), uint8(math.RoundHalfEven))
+	f.Add(int64(-25), uint8(1), int64(1), uint8(0), uint8(math.RoundHalfEven))
+	f.Add(int64(-35), uint8(1), int64(1), uint8(0), uint8(math.RoundHalfEven))
+	f.Add(int64(-55), uint8(1), int64(100), uint8(1), uint8(math.RoundUp))
+	f.Add(int64(1), uint8(0), int64(3), uint8(1), uint8(math.RoundUp))
+	f.Add(int64(10000001), uint8(8), int64(1), uint8(0), uint8(math.RoundCeiling))
+	f.Add(int64(-10000001), uint8(8), int64(1), uint8(0), uint8(math.RoundCeiling))
+	f.Add(int64(1), uint8(1), int64(4), uint8(0), uint8(math.RoundHalfDown))
+	f.Add(int64(10001), uint8(4), int64(3), uint8(0), uint8(math.RoundUnnecessary))
+	f.Add(int64(1<<62), uint8(18), int64(-(1 << 61)), uint8(0), uint8(math.RoundDown))
+}
+
+func FuzzDecimal_Add(f *testing.F) {
+	addBinarySeeds(f)
+	f.Fuzz(func(t *testing.T, a int64, aPrec uint8, b int64, bPrec uint8, _ uint8) {
+		x := decimaltest.Decimal(a, aPrec, maxFuzzPrecision)
+		y := decimaltest.Decimal(b, bPrec, maxFuzzPrecision)
+		decimaltest.CheckAdd(t, x, y)
+		decimaltest.CheckSub(t, x, y)
+	})
+}
+
+func FuzzDecimal_Mul(f *testing.F) {
+	addBinarySeeds(f)
+	f.Fuzz(func(t *testing.T, a int64, aPrec uint8, b int64, bPrec uint8, mode uint8) {
+		x := decimaltest.Decimal(a, aPrec, maxFuzzPrecision)
+		y := decimaltest.Decimal(b, bPrec, maxFuzzPrecision)
+		decimaltest.CheckMul(t, x, y, decimaltest.RoundingMode(mode))
+	})
+}
+
+func FuzzDecimal_Quo(f *testing.F) {
+	addBinarySeeds(f)
+	f.Fuzz(func(t *testing.T, a int64, aPrec uint8, b int64, bPrec uint8, mode uint8) {
+		x := decimaltest.Decimal(a, aPrec, maxFuzzPrecision)
+		y := decimaltest.Decimal(b, bPrec, maxFuzzPrecision)
+		if y.IsZero() {
+			t.Skip("division by zero")
+		}
+		decimaltest.CheckQuo(t, x, y, decimaltest.RoundingMode(mode))
+	})
+}
+
+func FuzzDecimal_Rescale(f *testing.F) {
+	addBinarySeeds(f)
+	f.Fuzz(func(t *testing.T, a int64, aPrec uint8, _ int64, prec uint8, mode uint8) {
+		x := decimaltest.Decimal(a, aPrec, maxFuzzPrecision)
+		decimaltest.CheckRescale(t, x, int(prec)%(maxFuzzPrecision+1), decimaltest.RoundingMode(mode))
+	})
+}
+
+func FuzzDecimal_SignificantFigures(f *testing.F) {
+	addBinarySeeds(f)
+	f.Add(int64(1111001001), uint8(6), int64(0), uint8(6), uint8(math.RoundUp))
+	f.Add(int64(-1111001001), uint8(6), int64(0), uint8(6), uint8(math.RoundHalfEven))
+	f.Fuzz(func(t *testing.T, a int64, aPrec uint8, _ int64, figures uint8, mode uint8) {
+		x := decimaltest.Decimal(a, aPrec, maxFuzzPrecision)
+		decimaltest.CheckSignificantFigures(t, x, int(figures)%30+1, decimaltest.RoundingMode(mode))
+	})
+}
+
+func FuzzDecimal_Marshal(f *testing.F) {
+	f.Add(int64(0), uint8(0))
+	f.Add(int64(0), uint8(18))
+	f.Add(int64(-1), uint8(0))
+	f.Add(int64(10001), uint8(4))
+	f.Add(int64(-10001), uint8(24))
+	f.Add(int64(1<<62), uint8(128))
+	f.Fuzz(func(t *testing.T, a int64, prec uint8) {
+		decimaltest.CheckMarshal(t, decimaltest.Decimal(a, prec, decimal.MaxPrecision))
+	})
+}
+
+func TestDecimal_Oracle(t *testing.T) {
+	values := []decimal.Decimal{
+		decimal.MustFromString("0"),
+		decimal.MustFromString("0.00"),
+		decimal.MustFromString("2.5"),
+		decimal.MustFromString("-2.5"),
+		decimal.MustFromString("-3.5"),
+		decimal.MustFromString("0.15"),
+		decimal.MustFromString("-0.15"),
+		decimal.MustFromString("1.0000001"),
+		decimal.MustFromString("-1.0000001"),
+		decimal.MustFromString("3"),
+		decimal.MustFromString("-7"),
+		decimal.MustFromString("0.0000000000000000000000000003"),
+	}
+	for _, mode := range decimaltest.RoundingModes {
+		for _, a := range values {
+			for _, b := range values {
+				decimaltest.CheckAdd(t, a, b)
+				decimaltest.CheckSub(t, a, b)
+				decimaltest.CheckMul(t, a, b, mode)
+				if !b.IsZero() {
+					decimaltest.CheckQuo(t, a, b, mode)
+				}
+			}
+			for prec := 0; prec <= 8; prec++ {
+				decimaltest.CheckRescale(t, a, prec, mode)
+				decimaltest.CheckSignificantFigures(t, a, prec+1, mode)
+			}
+		}
+	}
+}
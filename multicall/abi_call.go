@@ -46,6 +46,14 @@ func (A *ABIViewCall) Decode(raw []byte) ([]interface{}, error) {
 	return A.method.Outputs.Unpack(raw)
 }
 
+func (A *ABIViewCall) outputs() (abi.Arguments, error) {
+	return A.method.Outputs, nil
+}
+
+func (A *ABIViewCall) EstimatedReturnSize() int {
+	return estimateArgumentsSize(A.method.Outputs)
+}
+
 func NewABIViewCall(target common.Address, method abi.Method, arguments []interface{}, callback func(err error, returnValues []interface{}) error) ViewCall {
 	return &ABIViewCall{
 		method:    method,
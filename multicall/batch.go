@@ -0,0 +1,271 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	DefaultMaxCallsPerBatch      = 500
+	DefaultMaxCallDataPerBatch   = 128 * 1024
+	DefaultMaxReturnDataPerBatch = 1024 * 1024
+	DefaultBatchParallelism      = 4
+
+	// dynamicReturnSizeEstimate is the number of bytes assumed for the content of a
+	// dynamic return value (string, bytes, T[]) whose length is unknown before the call
+	dynamicReturnSizeEstimate = 256
+)
+
+var _ Caller = (*Batcher)(nil)
+
+// HeaderReader reads the latest header, ethclient.Client and the simulated backend satisfy it.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// ReturnSizeEstimator is implemented by view calls which know the approximate
+// size of their abi encoded return data.
+type ReturnSizeEstimator interface {
+	EstimatedReturnSize() int
+}
+
+type BatchOption func(*Batcher)
+
+// Batcher splits large ViewCalls into chunks which fit into a single eth_call,
+// runs the chunks concurrently and merges the results back in the original order.
+//
+// All chunks are pinned to the same block. The block is taken from
+// bind.CallOpts.BlockNumber if set, otherwise from the HeaderReader, otherwise from
+// the result of the first chunk. Block tags are resolved the same way.
+//
+// Callbacks of different chunks may run concurrently.
+type Batcher struct {
+	caller            Caller
+	headers           HeaderReader
+	maxCalls          int
+	maxCallDataSize   int
+	maxReturnDataSize int
+	parallelism       int
+}
+
+// NewBatcher creates a Batcher in front of a Caller
+func NewBatcher(caller Caller, opts ...BatchOption) *Batcher {
+	b := &Batcher{
+		caller:            caller,
+		maxCalls:          DefaultMaxCallsPerBatch,
+		maxCallDataSize:   DefaultMaxCallDataPerBatch,
+		maxReturnDataSize: DefaultMaxReturnDataPerBatch,
+		parallelism:       DefaultBatchParallelism,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// WithMaxCalls limits the number of calls in one chunk
+func WithMaxCalls(n int) BatchOption {
+	return func(b *Batcher) {
+		b.maxCalls = n
+	}
+}
+
+// WithMaxCallDataSize limits the total calldata bytes in one chunk
+func WithMaxCallDataSize(n int) BatchOption {
+	return func(b *Batcher) {
+		b.maxCallDataSize = n
+	}
+}
+
+// WithMaxReturnDataSize limits the total estimated return data bytes in one chunk
+func WithMaxReturnDataSize(n int) BatchOption {
+	return func(b *Batcher) {
+		b.maxReturnDataSize = n
+	}
+}
+
+// WithParallelism limits the number of chunks in flight
+func WithParallelism(n int) BatchOption {
+	return func(b *Batcher) {
+		b.parallelism = n
+	}
+}
+
+// WithHeaderReader sets the reader used to pin chunks to the latest block
+func WithHeaderReader(headers HeaderReader) BatchOption {
+	return func(b *Batcher) {
+		b.headers = headers
+	}
+}
+
+func (b *Batcher) Call(opts *bind.CallOpts, calls ViewCalls) (*Result, error) {
+	chunks, err := b.split(calls)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) <= 1 {
+		return b.caller.Call(opts, calls)
+	}
+
+	pinned := &bind.CallOpts{}
+	if opts != nil {
+		*pinned = *opts
+	}
+	ctx := pinned.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pinned.Context = ctx
+	pinned.Pending = false
+
+	results := make([]*Result, len(chunks))
+	remaining := chunks
+	// a block tag, see CallOptions, is resolved to the block it currently points to
+	unpinned := pinned.BlockNumber == nil || pinned.BlockNumber.Sign() < 0
+	if unpinned && b.headers != nil {
+		header, err := b.headers.HeaderByNumber(ctx, pinned.BlockNumber)
+		if err != nil {
+			return nil, fmt.Errorf("batcher: get header error: %w", err)
+		}
+		pinned.BlockNumber = header.Number
+		unpinned = false
+	}
+	if unpinned {
+		// Pin the remaining chunks to the block the first chunk was executed on
+		first, err := b.caller.Call(pinned, chunks[0])
+		if err != nil {
+			return nil, err
+		}
+		if first.BlockNumber == 0 {
+			return nil, errors.New("batcher: cannot pin chunks to a block, set CallOpts.BlockNumber or a HeaderReader")
+		}
+		pinned.BlockNumber = new(big.Int).SetUint64(first.BlockNumber)
+		results[0] = first
+		remaining = chunks[1:]
+	}
+
+	parallelism := b.parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		sem      = make(chan struct{}, parallelism)
+	)
+	for index := len(chunks) - len(remaining); index < len(chunks); index++ {
+		index := index
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			res, err := b.caller.Call(pinned, chunks[index])
+			if err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("batcher: call chunk %d error: %w", index, err)
+					cancel()
+				})
+				return
+			}
+			results[index] = res
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		BlockNumber: pinned.BlockNumber.Uint64(),
+		Calls:       make([]CallResult, 0, len(calls)),
+	}
+	for _, res := range results {
+		result.Calls = append(result.Calls, res.Calls...)
+	}
+	return result, nil
+}
+
+// split groups consecutive calls into chunks, every chunk keeps at least one call
+// even if that call alone exceeds the size limits
+func (b *Batcher) split(calls ViewCalls) ([]ViewCalls, error) {
+	chunks := make([]ViewCalls, 0)
+	current := ViewCalls{}
+	callDataSize, returnDataSize := 0, 0
+	for _, call := range calls {
+		data, err := call.CallData()
+		if err != nil {
+			return nil, err
+		}
+		returnSize := EstimateReturnSize(call)
+		if len(current) > 0 &&
+			(len(current)+1 > b.maxCalls ||
+				callDataSize+len(data) > b.maxCallDataSize ||
+				returnDataSize+returnSize > b.maxReturnDataSize) {
+			chunks = append(chunks, current)
+			current = ViewCalls{}
+			callDataSize, returnDataSize = 0, 0
+		}
+		current = append(current, call)
+		callDataSize += len(data)
+		returnDataSize += returnSize
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks, nil
+}
+
+// EstimateReturnSize returns the estimate of call if it is a ReturnSizeEstimator, or the size assumed for
+// dynamic return data, e.g. for the EstimatedReturnSize of a call wrapping another ViewCall
+func EstimateReturnSize(call ViewCall) int {
+	if estimator, ok := call.(ReturnSizeEstimator); ok {
+		return estimator.EstimatedReturnSize()
+	}
+	return dynamicReturnSizeEstimate
+}
+
+// estimateArgumentsSize returns the approximate abi encoded size of arguments
+func estimateArgumentsSize(arguments abi.Arguments) int {
+	size := 0
+	for _, argument := range arguments {
+		size += estimateTypeSize(argument.Type)
+	}
+	return size
+}
+
+func estimateTypeSize(t abi.Type) int {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy:
+		// offset + length + content
+		return 32 + 32 + dynamicReturnSizeEstimate
+	case abi.ArrayTy:
+		return t.Size * estimateTypeSize(*t.Elem)
+	case abi.TupleTy:
+		size := 0
+		for _, elem := range t.TupleElems {
+			size += estimateTypeSize(*elem)
+		}
+		return size
+	default:
+		return 32
+	}
+}
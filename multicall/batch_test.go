@@ -0,0 +1,133 @@
+package multicall
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCaller echoes the calldata of every call as its raw result
+type fakeCaller struct {
+	mu          sync.Mutex
+	blockNumber uint64
+	batches     []int
+	blocks      []*big.Int
+	err         error
+}
+
+func (f *fakeCaller) Call(opts *bind.CallOpts, calls ViewCalls) (*Result, error) {
+	if opts == nil {
+		opts = &bind.CallOpts{}
+	}
+	f.mu.Lock()
+	f.batches = append(f.batches, len(calls))
+	f.blocks = append(f.blocks, opts.BlockNumber)
+	f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	result := &Result{BlockNumber: f.blockNumber}
+	if opts.BlockNumber != nil {
+		result.BlockNumber = opts.BlockNumber.Uint64()
+	}
+	for _, call := range calls {
+		data, err := call.CallData()
+		if err != nil {
+			return nil, err
+		}
+		result.Calls = append(result.Calls, CallResult{Call: call, Raw: data, Success: true})
+	}
+	return result, nil
+}
+
+func balanceOfCalls(n int) ViewCalls {
+	calls := make(ViewCalls, n)
+	for i := range calls {
+		calls[i] = NewViewCall(
+			common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7"),
+			"balanceOf(address)(uint256)",
+			[]interface{}{common.BigToAddress(big.NewInt(int64(i)))},
+			nil,
+		)
+	}
+	return calls
+}
+
+func TestBatcher_Call(t *testing.T) {
+	t.Run("single chunk is passed through", func(t *testing.T) {
+		fake := &fakeCaller{}
+		res, err := NewBatcher(fake).Call(nil, balanceOfCalls(10))
+		require.NoError(t, err)
+		require.Len(t, res.Calls, 10)
+		require.Equal(t, []int{10}, fake.batches)
+	})
+
+	t.Run("split by count and keep order", func(t *testing.T) {
+		fake := &fakeCaller{}
+		calls := balanceOfCalls(25)
+		res, err := NewBatcher(fake, WithMaxCalls(10), WithParallelism(2)).Call(&bind.CallOpts{BlockNumber: big.NewInt(100)}, calls)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []int{10, 10, 5}, fake.batches)
+		require.Equal(t, uint64(100), res.BlockNumber)
+		require.Len(t, res.Calls, 25)
+		for i, call := range calls {
+			data, _ := call.CallData()
+			require.Equal(t, data, res.Calls[i].Raw)
+		}
+	})
+
+	t.Run("split by calldata size", func(t *testing.T) {
+		fake := &fakeCaller{}
+		// balanceOf(address) calldata is 36 bytes
+		_, err := NewBatcher(fake, WithMaxCallDataSize(36*3)).Call(&bind.CallOpts{BlockNumber: big.NewInt(1)}, balanceOfCalls(7))
+		require.NoError(t, err)
+		require.ElementsMatch(t, []int{3, 3, 1}, fake.batches)
+	})
+
+	t.Run("split by return data size", func(t *testing.T) {
+		fake := &fakeCaller{}
+		// uint256 return data is 32 bytes
+		_, err := NewBatcher(fake, WithMaxReturnDataSize(32*4)).Call(&bind.CallOpts{BlockNumber: big.NewInt(1)}, balanceOfCalls(8))
+		require.NoError(t, err)
+		require.ElementsMatch(t, []int{4, 4}, fake.batches)
+	})
+
+	t.Run("typed calls keep the return size estimate", func(t *testing.T) {
+		fake := &fakeCaller{}
+		calls := make(ViewCalls, 8)
+		for i := range calls {
+			calls[i] = NewTypedCall[*big.Int](common.Address{}, "balanceOf(address)(uint256)", []interface{}{common.Address{}}, nil)
+		}
+		_, err := NewBatcher(fake, WithMaxReturnDataSize(32*4)).Call(&bind.CallOpts{BlockNumber: big.NewInt(1)}, calls)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []int{4, 4}, fake.batches)
+	})
+
+	t.Run("pin to the block of the first chunk", func(t *testing.T) {
+		fake := &fakeCaller{blockNumber: 42}
+		res, err := NewBatcher(fake, WithMaxCalls(2)).Call(nil, balanceOfCalls(5))
+		require.NoError(t, err)
+		require.Equal(t, uint64(42), res.BlockNumber)
+		require.Nil(t, fake.blocks[0])
+		for _, block := range fake.blocks[1:] {
+			require.Equal(t, big.NewInt(42), block)
+		}
+	})
+
+	t.Run("cannot pin without block number", func(t *testing.T) {
+		fake := &fakeCaller{}
+		_, err := NewBatcher(fake, WithMaxCalls(2)).Call(nil, balanceOfCalls(5))
+		require.Error(t, err)
+	})
+
+	t.Run("chunk error fails the call", func(t *testing.T) {
+		fake := &fakeCaller{err: errors.New("gas cap exceeded")}
+		_, err := NewBatcher(fake, WithMaxCalls(2)).Call(&bind.CallOpts{BlockNumber: big.NewInt(1)}, balanceOfCalls(5))
+		require.ErrorIs(t, err, fake.err)
+	})
+}
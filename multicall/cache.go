@@ -0,0 +1,259 @@
+package multicall
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/hawkneo/utils/log"
+	"math/big"
+	"sync"
+)
+
+const DefaultCacheSize = 10_000
+
+var (
+	_ Caller       = (*CachedCaller)(nil)
+	_ CacheBackend = (*LRUCache)(nil)
+)
+
+// CacheBackend stores the encoded results of calls by key
+type CacheBackend interface {
+	// Get returns the values of the cached keys, keys which are not cached are missing from values
+	Get(ctx context.Context, keys []string) (values map[string][]byte, err error)
+	// Set caches values by key
+	Set(ctx context.Context, values map[string][]byte) error
+}
+
+type CacheOption func(*CachedCaller)
+
+// CachedCaller serves the calls of block-pinned requests from a CacheBackend and passes
+// the calls which are not cached to the Caller, in one request.
+//
+// The key of a call is (chainID, block number, target, gas limit, calldata), the entry holds the return data and
+// the gas used reported by AggregateMulticall. Only calls which did not revert are cached,
+// requests without bind.CallOpts.BlockNumber, or with a block tag, are passed through.
+// Pin to blocks deep enough not to be reorganized, the block hash is not part of the key.
+//
+// Cached calls are decoded and their callbacks run by the CachedCaller, once the request of the calls
+// which are not cached succeeded. The Result only carries the block hash if some calls were not cached.
+type CachedCaller struct {
+	caller  Caller
+	chainID *big.Int
+	backend CacheBackend
+	logger  log.Logger
+}
+
+// NewCachedCaller creates a CachedCaller in front of a Caller of chainID.
+// If backend is nil, an LRUCache of DefaultCacheSize calls is used.
+func NewCachedCaller(caller Caller, chainID *big.Int, backend CacheBackend, opts ...CacheOption) *CachedCaller {
+	if backend == nil {
+		backend = NewLRUCache(DefaultCacheSize)
+	}
+	c := &CachedCaller{
+		caller:  caller,
+		chainID: chainID,
+		backend: backend,
+		logger:  log.DefaultLogger,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithCacheLogger sets the logger of cache backend errors, which are treated as cache misses
+func WithCacheLogger(logger log.Logger) CacheOption {
+	return func(c *CachedCaller) {
+		c.logger = logger
+	}
+}
+
+func (c *CachedCaller) Call(opts *bind.CallOpts, calls ViewCalls) (*Result, error) {
+	if opts == nil || opts.BlockNumber == nil || opts.BlockNumber.Sign() < 0 {
+		return c.caller.Call(opts, calls)
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	keys := make([]string, len(calls))
+	for index, call := range calls {
+		data, err := call.CallData()
+		if err != nil {
+			return nil, err
+		}
+		keys[index] = c.key(opts.BlockNumber, call, data)
+	}
+	cached, err := c.backend.Get(ctx, keys)
+	if err != nil {
+		c.logger.Warnf("multicall cache: get error: %v", err)
+		cached = nil
+	}
+
+	result := &Result{
+		BlockNumber: opts.BlockNumber.Uint64(),
+		Calls:       make([]CallResult, len(calls)),
+	}
+	entries := make(map[int]cacheEntry)
+	missing := make([]int, 0)
+	for index := range calls {
+		value, ok := cached[keys[index]]
+		if !ok {
+			missing = append(missing, index)
+			continue
+		}
+		entry, err := decodeCacheEntry(value)
+		if err != nil {
+			c.logger.Warnf("multicall cache: decode %s error: %v", keys[index], err)
+			missing = append(missing, index)
+			continue
+		}
+		entries[index] = entry
+	}
+
+	if len(missing) > 0 {
+		missingCalls := make(ViewCalls, len(missing))
+		for i, index := range missing {
+			missingCalls[i] = calls[index]
+		}
+		missingResult, err := c.caller.Call(opts, missingCalls)
+		if err != nil {
+			return nil, err
+		}
+		if len(missingResult.Calls) != len(missingCalls) {
+			return nil, fmt.Errorf("multicall cache: got %d results for %d calls", len(missingResult.Calls), len(missingCalls))
+		}
+		result.BlockHash = missingResult.BlockHash
+
+		values := make(map[string][]byte)
+		for i, index := range missing {
+			callResult := missingResult.Calls[i]
+			result.Calls[index] = callResult
+			// the callback may turn a revert into a nil error, only the success flag of the call is trusted
+			if callResult.Success {
+				values[keys[index]] = encodeCacheEntry(callResult.Raw, callResult.GasUsed)
+			}
+		}
+		if len(values) > 0 {
+			if err := c.backend.Set(ctx, values); err != nil {
+				c.logger.Warnf("multicall cache: set error: %v", err)
+			}
+		}
+	}
+
+	// callbacks of cached calls run only once the whole request succeeded, like the ones of the missing calls
+	for index, call := range calls {
+		entry, ok := entries[index]
+		if !ok {
+			continue
+		}
+		returnValues, err := call.Decode(entry.raw)
+		if call.Callback() != nil {
+			err = call.Callback()(err, returnValues)
+		}
+		result.Calls[index] = CallResult{Call: call, Raw: entry.raw, Decoded: returnValues, Error: err, Success: true, GasUsed: entry.gasUsed}
+	}
+	return result, nil
+}
+
+// key returns the cache key of a call, the chain id and block number are a Redis Cluster hash tag, so that
+// the keys of a request are in the same hash slot. The gas limit is part of the key because AggregateMulticall
+// runs the call with it, a call may succeed with one limit and run out of gas with another.
+func (c *CachedCaller) key(blockNumber *big.Int, call ViewCall, callData []byte) string {
+	gasLimit := call.GasLimit()
+	if gasLimit == nil {
+		gasLimit = new(big.Int)
+	}
+	return fmt.Sprintf("{%s:%s}:%s:%s:%x", c.chainID, blockNumber, call.Target().Hex(), gasLimit, crypto.Keccak256(callData))
+}
+
+// cacheEntry is the cached result of a call
+type cacheEntry struct {
+	raw     []byte
+	gasUsed *big.Int // nil if the caller does not report the gas used
+}
+
+// encodeCacheEntry encodes raw after a flag byte, followed by the 32 bytes of gasUsed if it is set
+func encodeCacheEntry(raw []byte, gasUsed *big.Int) []byte {
+	if gasUsed == nil {
+		return append([]byte{0}, raw...)
+	}
+	value := append([]byte{1}, common.LeftPadBytes(gasUsed.Bytes(), 32)...)
+	return append(value, raw...)
+}
+
+func decodeCacheEntry(value []byte) (cacheEntry, error) {
+	switch {
+	case len(value) >= 1 && value[0] == 0:
+		return cacheEntry{raw: value[1:]}, nil
+	case len(value) >= 33 && value[0] == 1:
+		return cacheEntry{raw: value[33:], gasUsed: new(big.Int).SetBytes(value[1:33])}, nil
+	default:
+		return cacheEntry{}, fmt.Errorf("invalid cache entry of %d bytes", len(value))
+	}
+}
+
+// LRUCache is an in-memory CacheBackend which evicts the least recently used calls
+type LRUCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List // most recently used first
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+// NewLRUCache creates an LRUCache of at most size calls
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *LRUCache) Get(ctx context.Context, keys []string) (map[string][]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	values := make(map[string][]byte)
+	for _, key := range keys {
+		if element, ok := c.entries[key]; ok {
+			c.order.MoveToFront(element)
+			values[key] = element.Value.(*lruEntry).value
+		}
+	}
+	return values, nil
+}
+
+func (c *LRUCache) Set(ctx context.Context, values map[string][]byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, value := range values {
+		if element, ok := c.entries[key]; ok {
+			element.Value.(*lruEntry).value = value
+			c.order.MoveToFront(element)
+			continue
+		}
+		c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	}
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of cached calls
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,207 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hawkneo/utils/multicall/contract"
+	"github.com/stretchr/testify/require"
+)
+
+// failingCache fails every operation
+type failingCache struct{}
+
+func (failingCache) Get(ctx context.Context, keys []string) (map[string][]byte, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingCache) Set(ctx context.Context, values map[string][]byte) error {
+	return errors.New("connection refused")
+}
+
+func TestCachedCaller_Call(t *testing.T) {
+	fake := &fakeCaller{}
+	cache := NewLRUCache(100)
+	caller := NewCachedCaller(fake, big.NewInt(1), cache)
+	pinned := &bind.CallOpts{BlockNumber: big.NewInt(100)}
+
+	res, err := caller.Call(pinned, balanceOfCalls(3))
+	require.NoError(t, err)
+	require.Equal(t, []int{3}, fake.batches)
+	require.Equal(t, 3, cache.Len())
+
+	// only the calls which are not cached are passed to the caller
+	var callbackValues []interface{}
+	calls := balanceOfCalls(5)
+	calls[1] = NewViewCall(calls[1].Target(), "balanceOf(address)(uint256)", []interface{}{common.BigToAddress(big.NewInt(1))},
+		func(err error, returnValues []interface{}) error {
+			callbackValues = returnValues
+			return err
+		})
+	res, err = caller.Call(pinned, calls)
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 2}, fake.batches)
+	require.Equal(t, uint64(100), res.BlockNumber)
+	require.Len(t, res.Calls, 5)
+	for i, call := range calls {
+		data, _ := call.CallData()
+		require.Equal(t, data, res.Calls[i].Raw)
+		require.Equal(t, call, res.Calls[i].Call)
+	}
+	require.NotNil(t, res.Calls[0].Decoded)
+	require.Equal(t, res.Calls[1].Decoded, callbackValues)
+
+	res, err = caller.Call(pinned, calls)
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 2}, fake.batches)
+
+	// another block, another chain or an unpinned call are not cached
+	_, err = caller.Call(&bind.CallOpts{BlockNumber: big.NewInt(101)}, balanceOfCalls(1))
+	require.NoError(t, err)
+	_, err = NewCachedCaller(fake, big.NewInt(5), cache).Call(pinned, balanceOfCalls(1))
+	require.NoError(t, err)
+	_, err = caller.Call(nil, balanceOfCalls(1))
+	require.NoError(t, err)
+	_, err = caller.Call(&bind.CallOpts{BlockNumber: big.NewInt(-3)}, balanceOfCalls(1))
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 2, 1, 1, 1, 1}, fake.batches)
+	require.Equal(t, 7, cache.Len())
+
+	// a cache error is a miss
+	fake.batches = nil
+	_, err = NewCachedCaller(fake, big.NewInt(1), failingCache{}).Call(pinned, balanceOfCalls(2))
+	require.NoError(t, err)
+	require.Equal(t, []int{2}, fake.batches)
+}
+
+func TestCachedCaller_Failure(t *testing.T) {
+	backend := &fakeBackend{output: packAggregate3(t, 1,
+		contract.Multicall3Result{Success: true, ReturnData: common.LeftPadBytes([]byte{1}, 32)},
+		contract.Multicall3Result{Success: false},
+	)}
+	mc, err := NewMulticall3(backend, common.Address{})
+	require.NoError(t, err)
+	cache := NewLRUCache(100)
+
+	calls := balanceOfCalls(2)
+	calls[1] = NewViewCallWithAllowFailure(common.Address{}, "balanceOf(address)(uint256)", []interface{}{common.Address{}}, nil, true)
+	res, err := NewCachedCaller(mc, big.NewInt(1), cache).Call(&bind.CallOpts{BlockNumber: big.NewInt(1)}, calls)
+	require.NoError(t, err)
+	require.Error(t, res.Calls[1].Error)
+	require.Equal(t, 1, cache.Len())
+
+	// a callback ignoring the revert does not make the call cached
+	backend.output = packAggregate3(t, 1, contract.Multicall3Result{Success: false})
+	calls[1] = NewViewCallWithAllowFailure(common.Address{}, "balanceOf(address)(uint256)", []interface{}{common.Address{}},
+		func(err error, returnValues []interface{}) error {
+			return nil
+		}, true)
+	res, err = NewCachedCaller(mc, big.NewInt(1), cache).Call(&bind.CallOpts{BlockNumber: big.NewInt(1)}, calls)
+	require.NoError(t, err)
+	require.NoError(t, res.Calls[1].Error)
+	require.False(t, res.Calls[1].Success)
+	require.Equal(t, 1, cache.Len())
+}
+
+func TestCachedCaller_CallbackAfterRequest(t *testing.T) {
+	fake := &fakeCaller{}
+	cache := NewLRUCache(100)
+	pinned := &bind.CallOpts{BlockNumber: big.NewInt(100)}
+	_, err := NewCachedCaller(fake, big.NewInt(1), cache).Call(pinned, balanceOfCalls(1))
+	require.NoError(t, err)
+
+	called := 0
+	calls := balanceOfCalls(2)
+	calls[0] = NewViewCall(calls[0].Target(), "balanceOf(address)(uint256)", []interface{}{common.BigToAddress(big.NewInt(0))},
+		func(err error, returnValues []interface{}) error {
+			called++
+			return err
+		})
+	fake.err = errors.New("timeout")
+	_, err = NewCachedCaller(fake, big.NewInt(1), cache).Call(pinned, calls)
+	require.Error(t, err)
+	require.Equal(t, 0, called)
+
+	fake.err = nil
+	_, err = NewCachedCaller(fake, big.NewInt(1), cache).Call(pinned, calls)
+	require.NoError(t, err)
+	require.Equal(t, 1, called)
+}
+
+// gasCaller reports the gas used by every call, like AggregateMulticall
+type gasCaller struct {
+	*fakeCaller
+	gasUsed *big.Int
+}
+
+func (g *gasCaller) Call(opts *bind.CallOpts, calls ViewCalls) (*Result, error) {
+	result, err := g.fakeCaller.Call(opts, calls)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Calls {
+		result.Calls[i].GasUsed = g.gasUsed
+	}
+	return result, nil
+}
+
+func TestCachedCaller_GasLimit(t *testing.T) {
+	fake := &fakeCaller{}
+	cache := NewLRUCache(100)
+	caller := NewCachedCaller(&gasCaller{fakeCaller: fake, gasUsed: big.NewInt(21000)}, big.NewInt(1), cache)
+	pinned := &bind.CallOpts{BlockNumber: big.NewInt(100)}
+	withGasLimit := func(gasLimit int64) ViewCalls {
+		return ViewCalls{NewViewCallWithGasLimit(common.Address{}, "balanceOf(address)(uint256)", []interface{}{common.Address{}}, nil, big.NewInt(gasLimit))}
+	}
+
+	_, err := caller.Call(pinned, withGasLimit(100_000))
+	require.NoError(t, err)
+	// the gas used is cached with the return data
+	res, err := caller.Call(pinned, withGasLimit(100_000))
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, fake.batches)
+	require.Equal(t, big.NewInt(21000), res.Calls[0].GasUsed)
+
+	// the same call with another gas limit is not cached
+	_, err = caller.Call(pinned, withGasLimit(50_000))
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 1}, fake.batches)
+	require.Equal(t, 2, cache.Len())
+
+	// the gas used is not set if the caller did not report it
+	_, err = NewCachedCaller(fake, big.NewInt(1), cache).Call(pinned, balanceOfCalls(1))
+	require.NoError(t, err)
+	res, err = NewCachedCaller(fake, big.NewInt(1), cache).Call(pinned, balanceOfCalls(1))
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 1, 1}, fake.batches)
+	require.Nil(t, res.Calls[0].GasUsed)
+
+	// an invalid entry is a miss
+	data, err := withGasLimit(100_000)[0].CallData()
+	require.NoError(t, err)
+	require.NoError(t, cache.Set(context.Background(), map[string][]byte{caller.key(pinned.BlockNumber, withGasLimit(100_000)[0], data): {1, 2}}))
+	_, err = caller.Call(pinned, withGasLimit(100_000))
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 1, 1, 1}, fake.batches)
+}
+
+func TestLRUCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewLRUCache(2)
+	require.NoError(t, cache.Set(ctx, map[string][]byte{"a": {1}}))
+	require.NoError(t, cache.Set(ctx, map[string][]byte{"b": {2}}))
+	values, err := cache.Get(ctx, []string{"a"})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"a": {1}}, values)
+
+	// b is the least recently used
+	require.NoError(t, cache.Set(ctx, map[string][]byte{"c": {3}}))
+	values, err = cache.Get(ctx, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"a": {1}, "c": {3}}, values)
+	require.Equal(t, 2, cache.Len())
+}
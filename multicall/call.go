@@ -4,11 +4,8 @@ import (
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/hawkneo/utils/multicall/contract"
 	"math/big"
-	"regexp"
-	"strings"
 )
 
 type ViewCall interface {
@@ -88,33 +85,6 @@ func (call *SignatureViewCall) String() string {
 	return fmt.Sprintf("%s:%s", call.target, call.method)
 }
 
-var insideParens = regexp.MustCompile("\\(.*?\\)")
-
-func (call *SignatureViewCall) argumentTypes() []string {
-	rawArgs := insideParens.FindAllString(call.method, -1)[0]
-	rawArgs = strings.Replace(rawArgs, "(", "", -1)
-	rawArgs = strings.Replace(rawArgs, ")", "", -1)
-	if rawArgs == "" {
-		return []string{}
-	}
-	args := strings.Split(rawArgs, ",")
-	for index, arg := range args {
-		args[index] = strings.Trim(arg, " ")
-	}
-	return args
-}
-
-func (call *SignatureViewCall) returnTypes() []string {
-	rawArgs := insideParens.FindAllString(call.method, -1)[1]
-	rawArgs = strings.Replace(rawArgs, "(", "", -1)
-	rawArgs = strings.Replace(rawArgs, ")", "", -1)
-	args := strings.Split(rawArgs, ",")
-	for index, arg := range args {
-		args[index] = strings.Trim(arg, " ")
-	}
-	return args
-}
-
 func (call *SignatureViewCall) CallData() ([]byte, error) {
 	argsSuffix, err := call.argsCallData()
 	if err != nil {
@@ -132,66 +102,56 @@ func (call *SignatureViewCall) CallData() ([]byte, error) {
 	return payload, nil
 }
 
+// signature parses the method, the outputs are left unresolved if a custom decoder is used
+func (call *SignatureViewCall) signature() (*Signature, error) {
+	return parseSignature(call.method, call.decoder == nil)
+}
+
 func (call *SignatureViewCall) methodCallData() ([]byte, error) {
-	methodParts := strings.Split(call.method, ")(")
-	var method string
-	if len(methodParts) > 1 {
-		method = fmt.Sprintf("%s)", methodParts[0])
-	} else {
-		method = methodParts[0]
+	sig, err := call.signature()
+	if err != nil {
+		return nil, err
 	}
-	hash := crypto.Keccak256([]byte(method))
-	return hash[0:4], nil
+	return sig.Method().ID, nil
 }
 
 func (call *SignatureViewCall) argsCallData() ([]byte, error) {
-	argTypes := call.argumentTypes()
-	if len(argTypes) != len(call.arguments) {
-		return nil, fmt.Errorf("number of argument types doesn't match with number of arguments with method %s", call.method)
+	sig, err := call.signature()
+	if err != nil {
+		return nil, err
 	}
-	argumentValues := make([]interface{}, len(call.arguments))
-	arguments := make(abi.Arguments, len(call.arguments))
-
-	for index, argTypeStr := range argTypes {
-		argType, err := abi.NewType(argTypeStr, "", nil)
-		if err != nil {
-			return nil, err
-		}
-
-		arguments[index] = abi.Argument{Type: argType}
-		argumentValues[index] = call.arguments[index]
-		if err != nil {
-			return nil, err
-		}
+	if len(sig.Inputs) != len(call.arguments) {
+		return nil, fmt.Errorf("number of argument types doesn't match with number of arguments with method %s", call.method)
 	}
-
-	return arguments.Pack(argumentValues...)
+	return sig.Inputs.Pack(call.arguments...)
 }
 
 func (call *SignatureViewCall) Decode(raw []byte) ([]interface{}, error) {
 	if call.decoder != nil {
 		return call.decoder(raw)
 	}
-	retTypes := call.returnTypes()
-	args := make(abi.Arguments, 0, 0)
-	for index, retTypeStr := range retTypes {
-		retType, err := abi.NewType(retTypeStr, "", nil)
-		if err != nil {
-			return nil, err
-		}
-		args = append(args, abi.Argument{Name: fmt.Sprintf("ret%d", index), Type: retType})
+	args, err := call.outputs()
+	if err != nil {
+		return nil, err
 	}
-	decoded := make(map[string]interface{})
-	err := args.UnpackIntoMap(decoded, raw)
+	return args.Unpack(raw)
+}
+
+// outputs returns the return arguments of the method
+func (call *SignatureViewCall) outputs() (abi.Arguments, error) {
+	sig, err := ParseSignature(call.method)
 	if err != nil {
 		return nil, err
 	}
-	returns := make([]interface{}, len(retTypes))
-	for index := range retTypes {
-		key := fmt.Sprintf("ret%d", index)
-		returns[index] = decoded[key]
+	return sig.Outputs, nil
+}
+
+func (call *SignatureViewCall) EstimatedReturnSize() int {
+	args, err := call.outputs()
+	if err != nil {
+		return dynamicReturnSizeEstimate
 	}
-	return returns, nil
+	return estimateArgumentsSize(args)
 }
 
 func (call *SignatureViewCall) GasLimit() *big.Int {
@@ -213,22 +173,26 @@ func (call *SignatureViewCall) AllowFailure() bool {
 	return call.allowFailure
 }
 
-func (calls ViewCalls) decode(callResponse *contract.MultiCallResult) (*Result, error) {
+func (calls ViewCalls) decode(callResponse *contract.MultiCallResult, customErrors *abi.ABI) (*Result, error) {
 	result := &Result{}
 	result.BlockNumber = callResponse.BlockNumber.Uint64()
 	result.Calls = make([]CallResult, len(calls))
 	for index, call := range calls {
 		callResult := CallResult{
-			Call: call,
-			Raw:  callResponse.ReturnData[index].ReturnData,
+			Call:    call,
+			Raw:     callResponse.ReturnData[index].ReturnData,
+			GasUsed: callResponse.ReturnData[index].GasUsed,
+			Success: callResponse.ReturnData[index].Success,
 		}
 		var err error = nil
 		var returnValues []interface{} = nil
 		if callResponse.ReturnData[index].Success {
 			returnValues, err = call.Decode(callResponse.ReturnData[index].ReturnData)
 		} else {
-			err = fmt.Errorf("call contract error, returns: %s, gaslimit: %s, gasused: %s",
-				string(callResponse.ReturnData[index].ReturnData), call.GasLimit(), callResponse.ReturnData[index].GasUsed)
+			revertErr := DecodeRevert(callResponse.ReturnData[index].ReturnData, customErrors)
+			revertErr.GasLimit = call.GasLimit()
+			revertErr.GasUsed = callResponse.ReturnData[index].GasUsed
+			err = revertErr
 		}
 		callResult.Error = err
 		callResult.Decoded = returnValues
@@ -240,14 +204,15 @@ func (calls ViewCalls) decode(callResponse *contract.MultiCallResult) (*Result,
 	return result, nil
 }
 
-func (calls ViewCalls) decode3(callResponse *contract.MultiCall3Result) (*Result, error) {
+func (calls ViewCalls) decode3(callResponse *contract.MultiCall3BlockResult, customErrors *abi.ABI) (*Result, error) {
 	result := &Result{}
-	result.BlockNumber = 0
+	result.BlockNumber = callResponse.BlockNumber.Uint64()
 	result.Calls = make([]CallResult, len(calls))
 	for index, call := range calls {
 		callResult := CallResult{
-			Call: call,
-			Raw:  callResponse.ReturnData[index].ReturnData,
+			Call:    call,
+			Raw:     callResponse.ReturnData[index].ReturnData,
+			Success: callResponse.ReturnData[index].Success,
 		}
 
 		var err error = nil
@@ -255,8 +220,7 @@ func (calls ViewCalls) decode3(callResponse *contract.MultiCall3Result) (*Result
 		if callResponse.ReturnData[index].Success {
 			returnValues, err = call.Decode(callResponse.ReturnData[index].ReturnData)
 		} else {
-			err = fmt.Errorf("call contract error, returns: %s",
-				string(callResponse.ReturnData[index].ReturnData))
+			err = DecodeRevert(callResponse.ReturnData[index].ReturnData, customErrors)
 		}
 		callResult.Error = err
 		callResult.Decoded = returnValues
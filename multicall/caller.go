@@ -0,0 +1,68 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+)
+
+// Caller executes ViewCalls within one eth_call, it is implemented by Multicall and Multicall3
+type Caller interface {
+	Call(opts *bind.CallOpts, calls ViewCalls) (*Result, error)
+}
+
+// NewCaller detects which multicall contract is deployed at addr by calling it with no calls, and
+// returns the matching Caller. Multicall3 is tried first.
+func NewCaller(ctx context.Context, eth bind.ContractCaller, addr common.Address) (Caller, error) {
+	code, err := eth.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("no contract code at %s", addr)
+	}
+
+	opts := &bind.CallOpts{Context: ctx}
+	multicall3, err := NewMulticall3(eth, addr)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := multicall3.Call(opts, nil); err == nil {
+		return multicall3, nil
+	}
+
+	multicall, err := NewMulticall(eth, addr)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := multicall.Call(opts, nil); err == nil {
+		return multicall, nil
+	}
+	return nil, fmt.Errorf("contract at %s is neither Multicall3 nor AggregateMulticall", addr)
+}
+
+// fillBlockHash sets the block hash of result with the header of its block if backend is a HeaderReader.
+// Pending calls and calls with block overrides run on a block which is not in the chain, their hash stays empty.
+func fillBlockHash(backend bind.ContractCaller, opts *bind.CallOpts, result *Result) error {
+	reader, ok := backend.(HeaderReader)
+	if !ok || (opts != nil && opts.Pending) {
+		return nil
+	}
+	ctx := context.Background()
+	if opts != nil && opts.Context != nil {
+		ctx = opts.Context
+	}
+	header, err := reader.HeaderByNumber(ctx, new(big.Int).SetUint64(result.BlockNumber))
+	if errors.Is(err, ethereum.NotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read header of block %d error: %w", result.BlockNumber, err)
+	}
+	result.BlockHash = header.Hash()
+	return nil
+}
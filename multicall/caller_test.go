@@ -0,0 +1,120 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hawkneo/utils/multicall/contract"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackend serves fixed code and a fixed eth_call response, or the response of the called selector
+type fakeBackend struct {
+	code      []byte
+	output    []byte
+	err       error
+	selectors map[[4]byte][]byte
+	data      []byte // call data of the last eth_call
+}
+
+func (f *fakeBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return f.code, nil
+}
+
+func (f *fakeBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	f.data = call.Data
+	if f.selectors != nil {
+		var selector [4]byte
+		copy(selector[:], call.Data)
+		if output, ok := f.selectors[selector]; ok {
+			return output, nil
+		}
+		return nil, errors.New("execution reverted")
+	}
+	return f.output, f.err
+}
+
+// packAggregate3 packs the output of aggregate3 with results followed by the result of getBlockNumber
+func packAggregate3(t *testing.T, blockNumber int64, results ...contract.Multicall3Result) []byte {
+	parsed, err := contract.Multicall3MetaData.GetAbi()
+	require.NoError(t, err)
+	results = append(results, contract.Multicall3Result{Success: true, ReturnData: common.LeftPadBytes(big.NewInt(blockNumber).Bytes(), 32)})
+	output, err := parsed.Methods["aggregate3"].Outputs.Pack(results)
+	require.NoError(t, err)
+	return output
+}
+
+func TestNewCaller(t *testing.T) {
+	ctx := context.Background()
+	multicall3ABI, err := contract.Multicall3MetaData.GetAbi()
+	require.NoError(t, err)
+	multicallABI, err := contract.AggregateMultiCallContractMetaData.GetAbi()
+	require.NoError(t, err)
+	multicallOutput, err := multicallABI.Methods["multicall"].Outputs.Pack(big.NewInt(1), []contract.AggregateMulticallResult{})
+	require.NoError(t, err)
+
+	var aggregate3, multicall [4]byte
+	copy(aggregate3[:], multicall3ABI.Methods["aggregate3"].ID)
+	copy(multicall[:], multicallABI.Methods["multicall"].ID)
+	code := []byte{0x60, 0x80}
+
+	caller, err := NewCaller(ctx, &fakeBackend{code: code, selectors: map[[4]byte][]byte{
+		aggregate3: packAggregate3(t, 1),
+		multicall:  multicallOutput,
+	}}, common.Address{})
+	require.NoError(t, err)
+	require.IsType(t, &Multicall3{}, caller)
+
+	caller, err = NewCaller(ctx, &fakeBackend{code: code, selectors: map[[4]byte][]byte{multicall: multicallOutput}}, common.Address{})
+	require.NoError(t, err)
+	require.IsType(t, &Multicall{}, caller)
+
+	_, err = NewCaller(ctx, &fakeBackend{}, common.Address{})
+	require.Error(t, err)
+
+	_, err = NewCaller(ctx, &fakeBackend{code: code, selectors: map[[4]byte][]byte{}}, common.Address{})
+	require.Error(t, err)
+
+	// code which only contains the selector, e.g. in a push, is not enough
+	_, err = NewCaller(ctx, &fakeBackend{code: append([]byte{0x63}, aggregate3[:]...), output: []byte{1}}, common.Address{})
+	require.Error(t, err)
+}
+
+func TestMulticall3_BlockNumber(t *testing.T) {
+	uint256One := common.LeftPadBytes([]byte{1}, 32)
+	backend := &fakeBackend{output: packAggregate3(t, 17,
+		contract.Multicall3Result{Success: true, ReturnData: uint256One},
+		contract.Multicall3Result{Success: false},
+	)}
+	mc, err := NewMulticall3(backend, common.Address{})
+	require.NoError(t, err)
+
+	calls := balanceOfCalls(2)
+	calls[1] = NewViewCallWithAllowFailure(common.Address{}, "balanceOf(address)(uint256)", []interface{}{common.Address{}}, nil, true)
+	res, err := mc.Call(nil, calls)
+	require.NoError(t, err)
+	require.Equal(t, uint64(17), res.BlockNumber)
+	require.NoError(t, res.Calls[0].Error)
+	require.Equal(t, big.NewInt(1), res.Calls[0].Decoded[0])
+	require.Error(t, res.Calls[1].Error)
+
+	// AllowFailure is sent on-chain, followed by getBlockNumber
+	multicall3ABI, err := contract.Multicall3MetaData.GetAbi()
+	require.NoError(t, err)
+	args, err := multicall3ABI.Methods["aggregate3"].Inputs.Unpack(backend.data[4:])
+	require.NoError(t, err)
+	call3s := *abi.ConvertType(args[0], new([]contract.Multicall3Call3)).(*[]contract.Multicall3Call3)
+	require.Len(t, call3s, 3)
+	require.False(t, call3s[0].AllowFailure)
+	require.True(t, call3s[1].AllowFailure)
+	require.Equal(t, multicall3ABI.Methods["getBlockNumber"].ID, call3s[2].CallData)
+
+	backend.output = packAggregate3(t, 17, contract.Multicall3Result{Success: true})
+	_, err = mc.Call(nil, calls)
+	require.ErrorContains(t, err, "got 2 results for 3 calls")
+}
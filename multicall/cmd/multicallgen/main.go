@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"github.com/hawkneo/utils/multicall/gen"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:   "multicallgen",
+		Short: "Generate typed multicall view calls from a contract ABI",
+		Long: strings.TrimSpace(fmt.Sprintf(`
+Generate a ViewCall constructor for every view and pure function of a contract ABI,
+and a result struct for every function with multiple return values or tuples.
+
+Example:
+  $ %s --abi ERC20.json --pkg token --type ERC20 --out erc20_calls.go
+`, os.Args[0],
+		)),
+		Args: cobra.NoArgs,
+	}
+
+	abiPath := rootCmd.Flags().String("abi", "", "path of the contract ABI JSON, or of a build artifact with an \"abi\" field")
+	pkg := rootCmd.Flags().String("pkg", "", "package name of the generated file")
+	typeName := rootCmd.Flags().String("type", "", "name of the generated contract type")
+	out := rootCmd.Flags().String("out", "", "output file, the generated code is printed if not set")
+	_ = rootCmd.MarkFlagRequired("abi")
+	_ = rootCmd.MarkFlagRequired("pkg")
+	_ = rootCmd.MarkFlagRequired("type")
+
+	rootCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(*abiPath)
+		if err != nil {
+			return fmt.Errorf("failed to read abi file: %w", err)
+		}
+		source, err := gen.Generate(gen.Options{ABI: data, Package: *pkg, Type: *typeName})
+		if err != nil {
+			return err
+		}
+		if *out == "" {
+			_, err = os.Stdout.Write(source)
+			return err
+		}
+		if err := os.WriteFile(*out, source, 0644); err != nil {
+			return fmt.Errorf("failed to write generated file: %w", err)
+		}
+		return nil
+	}
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -1,7 +1,13 @@
 package contract
 
-import "github.com/ethereum/go-ethereum/accounts/abi/bind"
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"math/big"
+)
 
+// Aggregate3Static is a free data retrieval call binding the contract method 0x82ad56cb.
+//
+// Solidity: function aggregate3((address,bool,bytes)[] calls) payable returns((bool,bytes)[] returnData)
 func (mc *Multicall3Caller) Aggregate3Static(
 	opts *bind.CallOpts,
 	calls []Multicall3Call3,
@@ -21,3 +27,8 @@ func (mc *Multicall3Caller) Aggregate3Static(
 type MultiCall3Result struct {
 	ReturnData []Multicall3Result
 }
+
+type MultiCall3BlockResult struct {
+	BlockNumber *big.Int
+	ReturnData  []Multicall3Result
+}
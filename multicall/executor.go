@@ -0,0 +1,234 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+	"time"
+)
+
+const (
+	DefaultRetries         = 3
+	DefaultRetryBackoff    = 200 * time.Millisecond
+	DefaultMaxRetryBackoff = 5 * time.Second
+)
+
+var _ Caller = (*Executor)(nil)
+
+type ExecutorOption func(*Executor)
+
+// Executor runs ViewCalls with a failure policy instead of losing the whole Result to one failure.
+//
+// Transport errors, e.g. timeouts or rate limits, are retried with exponential backoff, then retried
+// on the fallback Caller. A batch which reverts, e.g. because a call which does not allow failure
+// reverts, is bisected until the poison calls are isolated. Isolated calls, calls whose calldata cannot
+// be packed and batches which still fail after the retries get their error in CallResult.Error, and
+// their callback is called with it.
+//
+// Call only returns an error if the whole batch fails with a transport error.
+//
+// Bisected batches are pinned to the block of the first batch which succeeds, the same way as Batcher.
+type Executor struct {
+	caller     Caller
+	fallback   Caller
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// NewExecutor creates an Executor in front of a Caller
+func NewExecutor(caller Caller, opts ...ExecutorOption) *Executor {
+	e := &Executor{
+		caller:     caller,
+		retries:    DefaultRetries,
+		backoff:    DefaultRetryBackoff,
+		maxBackoff: DefaultMaxRetryBackoff,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// WithRetries sets the number of retries of a transport error per Caller
+func WithRetries(n int) ExecutorOption {
+	return func(e *Executor) {
+		e.retries = n
+	}
+}
+
+// WithBackoff sets the delay before the first retry, which doubles with every retry up to max
+func WithBackoff(initial, max time.Duration) ExecutorOption {
+	return func(e *Executor) {
+		e.backoff = initial
+		e.maxBackoff = max
+	}
+}
+
+// WithFallback sets the Caller used when the retries of the Caller are exhausted,
+// usually the same contract on a secondary bind.ContractCaller:
+//
+//	fallback, err := NewMulticall3(secondaryClient, address)
+func WithFallback(caller Caller) ExecutorOption {
+	return func(e *Executor) {
+		e.fallback = caller
+	}
+}
+
+// execution is the state of one Executor.Call
+type execution struct {
+	opts   *bind.CallOpts
+	ctx    context.Context
+	result *Result
+	pinned bool // opts.BlockNumber is the block of a successful batch
+}
+
+func (e *Executor) Call(opts *bind.CallOpts, calls ViewCalls) (*Result, error) {
+	pinned := &bind.CallOpts{}
+	if opts != nil {
+		*pinned = *opts
+	}
+	ctx := pinned.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	exec := &execution{
+		opts:   pinned,
+		ctx:    ctx,
+		result: &Result{Calls: make([]CallResult, len(calls))},
+	}
+	if pinned.BlockNumber != nil && pinned.BlockNumber.Sign() >= 0 {
+		exec.result.BlockNumber = pinned.BlockNumber.Uint64()
+	}
+
+	// calls whose calldata cannot be packed would fail every batch they are part of
+	indexes := make([]int, 0, len(calls))
+	for index, call := range calls {
+		if _, err := call.CallData(); err != nil {
+			exec.result.Calls[index] = failedCallResult(call, err)
+			continue
+		}
+		indexes = append(indexes, index)
+	}
+	if err := e.bisect(exec, calls, indexes, true); err != nil {
+		return nil, err
+	}
+	return exec.result, nil
+}
+
+// bisect runs the calls at indexes and splits them in halves while they revert
+func (e *Executor) bisect(exec *execution, calls ViewCalls, indexes []int, top bool) error {
+	if len(indexes) == 0 {
+		return nil
+	}
+	batch := make(ViewCalls, len(indexes))
+	for i, index := range indexes {
+		batch[i] = calls[index]
+	}
+
+	res, err := e.try(exec, batch)
+	if err == nil && len(res.Calls) != len(batch) {
+		err = fmt.Errorf("executor: got %d results for %d calls", len(res.Calls), len(batch))
+	}
+	if err == nil {
+		if !exec.pinned {
+			exec.pinned = true
+			exec.opts.BlockNumber = new(big.Int).SetUint64(res.BlockNumber)
+			exec.opts.Pending = false
+			exec.result.BlockNumber = res.BlockNumber
+			exec.result.BlockHash = res.BlockHash
+		}
+		for i, index := range indexes {
+			exec.result.Calls[index] = res.Calls[i]
+		}
+		return nil
+	}
+
+	if !isRevert(err) || len(indexes) == 1 {
+		if top && !isRevert(err) {
+			return err
+		}
+		for _, index := range indexes {
+			exec.result.Calls[index] = failedCallResult(calls[index], err)
+		}
+		return nil
+	}
+	middle := len(indexes) / 2
+	if err := e.bisect(exec, calls, indexes[:middle], false); err != nil {
+		return err
+	}
+	return e.bisect(exec, calls, indexes[middle:], false)
+}
+
+// try runs calls on the Caller, then on the fallback, retrying transport errors
+func (e *Executor) try(exec *execution, calls ViewCalls) (*Result, error) {
+	res, err := e.retry(exec, e.caller, calls)
+	if err == nil || isRevert(err) || e.fallback == nil || exec.ctx.Err() != nil {
+		return res, err
+	}
+	res, fallbackErr := e.retry(exec, e.fallback, calls)
+	if fallbackErr != nil && !isRevert(fallbackErr) {
+		return nil, fmt.Errorf("executor: fallback error: %v, caller error: %w", fallbackErr, err)
+	}
+	return res, fallbackErr
+}
+
+func (e *Executor) retry(exec *execution, caller Caller, calls ViewCalls) (*Result, error) {
+	backoff := e.backoff
+	for attempt := 0; ; attempt++ {
+		res, err := caller.Call(exec.opts, calls)
+		if err == nil || isRevert(err) || attempt >= e.retries {
+			return res, err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-exec.ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > e.maxBackoff {
+			backoff = e.maxBackoff
+		}
+	}
+}
+
+// revertErrorCode is the JSON-RPC error code of go-ethereum for a reverted eth_call, the revert data is
+// the data of the error
+const revertErrorCode = 3
+
+// executionErrorCode is the JSON-RPC error code of go-ethereum for a failed eth_call, e.g. a revert without
+// data by revert() or require(condition), whose message is vm.ErrExecutionReverted
+const executionErrorCode = -32000
+
+// isRevert reports whether err is a revert of the eth_call or of a call which does not allow failure,
+// which fails again on retry
+func isRevert(err error) bool {
+	var revertErr *RevertError
+	if errors.As(err, &revertErr) || errors.Is(err, vm.ErrExecutionReverted) {
+		return true
+	}
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	switch rpcErr.ErrorCode() {
+	case revertErrorCode:
+		var dataErr rpc.DataError
+		return errors.As(err, &dataErr) && dataErr.ErrorData() != nil
+	case executionErrorCode:
+		return rpcErr.Error() == vm.ErrExecutionReverted.Error()
+	}
+	return false
+}
+
+func failedCallResult(call ViewCall, err error) CallResult {
+	if call.Callback() != nil {
+		err = call.Callback()(err, nil)
+	}
+	return CallResult{Call: call, Error: err}
+}
@@ -0,0 +1,119 @@
+package multicall
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyCaller fails the first failures calls with a transport error, and reverts batches containing poison
+type flakyCaller struct {
+	fakeCaller
+	failures int
+	poison   [][]byte
+}
+
+func (f *flakyCaller) Call(opts *bind.CallOpts, calls ViewCalls) (*Result, error) {
+	f.mu.Lock()
+	if f.failures > 0 {
+		f.failures--
+		f.batches = append(f.batches, len(calls))
+		f.mu.Unlock()
+		return nil, errors.New("429 Too Many Requests")
+	}
+	f.mu.Unlock()
+	for _, call := range calls {
+		data, _ := call.CallData()
+		for _, poison := range f.poison {
+			if bytes.Equal(data, poison) {
+				f.mu.Lock()
+				f.batches = append(f.batches, len(calls))
+				f.mu.Unlock()
+				return nil, &RevertError{Reason: "poison"}
+			}
+		}
+	}
+	return f.fakeCaller.Call(opts, calls)
+}
+
+func TestExecutor_Call(t *testing.T) {
+	backoff := WithBackoff(time.Millisecond, 2*time.Millisecond)
+
+	t.Run("retry transport errors", func(t *testing.T) {
+		fake := &flakyCaller{fakeCaller: fakeCaller{blockNumber: 7}, failures: 2}
+		res, err := NewExecutor(fake, backoff).Call(nil, balanceOfCalls(3))
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), res.BlockNumber)
+		require.Equal(t, []int{3, 3, 3}, fake.batches)
+		for _, call := range res.Calls {
+			require.NoError(t, call.Error)
+		}
+	})
+
+	t.Run("fall back after the retries", func(t *testing.T) {
+		primary := &flakyCaller{failures: 10}
+		fallback := &fakeCaller{blockNumber: 7}
+		res, err := NewExecutor(primary, backoff, WithRetries(1), WithFallback(fallback)).Call(nil, balanceOfCalls(3))
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), res.BlockNumber)
+		require.Equal(t, []int{3, 3}, primary.batches)
+		require.Equal(t, []int{3}, fallback.batches)
+
+		fallback.err = errors.New("connection refused")
+		_, err = NewExecutor(primary, backoff, WithRetries(0), WithFallback(fallback)).Call(nil, balanceOfCalls(3))
+		require.ErrorContains(t, err, "Too Many Requests")
+		require.ErrorContains(t, err, "connection refused")
+	})
+
+	t.Run("bisect to isolate poison calls", func(t *testing.T) {
+		calls := balanceOfCalls(8)
+		poison1, _ := calls[2].CallData()
+		poison2, _ := calls[5].CallData()
+		fake := &flakyCaller{fakeCaller: fakeCaller{blockNumber: 7}, poison: [][]byte{poison1, poison2}}
+
+		var callbackErr error
+		calls[5] = NewViewCall(calls[5].Target(), "balanceOf(address)(uint256)", []interface{}{calls[5].(*SignatureViewCall).arguments[0]},
+			func(err error, returnValues []interface{}) error {
+				callbackErr = err
+				return err
+			})
+		res, err := NewExecutor(fake, backoff).Call(nil, calls)
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), res.BlockNumber)
+		for i, call := range res.Calls {
+			require.Equal(t, calls[i], call.Call)
+			if i == 2 || i == 5 {
+				var revertErr *RevertError
+				require.True(t, errors.As(call.Error, &revertErr))
+				require.Equal(t, "poison", revertErr.Reason)
+				continue
+			}
+			require.NoError(t, call.Error)
+			data, _ := calls[i].CallData()
+			require.Equal(t, data, call.Raw)
+		}
+		require.Equal(t, res.Calls[5].Error, callbackErr)
+		// bisected batches are pinned to the block of the first successful batch
+		require.Nil(t, fake.blocks[0])
+		for _, block := range fake.blocks[1:] {
+			require.Equal(t, big.NewInt(7), block)
+		}
+	})
+
+	t.Run("invalid calldata", func(t *testing.T) {
+		fake := &fakeCaller{}
+		calls := balanceOfCalls(3)
+		calls[1] = NewViewCall(calls[1].Target(), "balanceOf(address)(uint256)", []interface{}{"not an address"}, nil)
+		res, err := NewExecutor(fake).Call(&bind.CallOpts{BlockNumber: big.NewInt(9)}, calls)
+		require.NoError(t, err)
+		require.Equal(t, []int{2}, fake.batches)
+		require.Equal(t, uint64(9), res.BlockNumber)
+		require.Error(t, res.Calls[1].Error)
+		require.NoError(t, res.Calls[2].Error)
+	})
+}
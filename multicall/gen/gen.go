@@ -0,0 +1,359 @@
+// Package gen generates typed ViewCall constructors and result structs from a contract ABI.
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Options of the generated code
+type Options struct {
+	// ABI is the contract ABI JSON, or a build artifact with an "abi" field
+	ABI []byte
+	// Package is the package name of the generated file
+	Package string
+	// Type is the name of the generated contract type, e.g. ERC20
+	Type string
+}
+
+type contractData struct {
+	Package    string
+	Type       string
+	ABIVar     string
+	ABI        string
+	MethodsVar string
+	Imports    []string
+	Methods    []methodData
+	Structs    []structData
+}
+
+type methodData struct {
+	Name       string // Go name
+	ABIName    string // key in abi.ABI.Methods
+	Signature  string // solidity signature, for the doc comment
+	Inputs     []fieldData
+	ResultType string
+}
+
+type structData struct {
+	Name   string
+	Doc    string
+	Fields []fieldData
+}
+
+type fieldData struct {
+	Name string
+	Type string
+}
+
+// generator maps ABI types to Go types, collecting the imports and tuple structs they need
+type generator struct {
+	typeName string
+	imports  map[string]bool
+	structs  map[string]structData
+}
+
+// Generate returns the formatted Go source of the view calls of every view and pure function in the ABI
+func Generate(opts Options) ([]byte, error) {
+	if opts.Package == "" || opts.Type == "" {
+		return nil, fmt.Errorf("gen: package and type are required")
+	}
+	if !token.IsIdentifier(opts.Type) || !token.IsExported(opts.Type) {
+		return nil, fmt.Errorf("gen: type %q is not an exported identifier", opts.Type)
+	}
+	rawABI, err := extractABI(opts.ABI)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(bytes.NewReader(rawABI))
+	if err != nil {
+		return nil, fmt.Errorf("gen: parse abi error: %w", err)
+	}
+
+	g := &generator{
+		typeName: opts.Type,
+		imports: map[string]bool{
+			"strings": true,
+			"github.com/ethereum/go-ethereum/accounts/abi": true,
+			"github.com/ethereum/go-ethereum/common":       true,
+			"github.com/hawkneo/utils/multicall":           true,
+		},
+		structs: make(map[string]structData),
+	}
+	data := contractData{
+		Package:    opts.Package,
+		Type:       opts.Type,
+		ABIVar:     opts.Type + "ABI",
+		ABI:        strconv.Quote(string(rawABI)),
+		MethodsVar: strings.ToLower(opts.Type) + "Methods",
+	}
+
+	names := make([]string, 0, len(parsed.Methods))
+	for name, method := range parsed.Methods {
+		if method.IsConstant() && len(method.Outputs) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		method, err := g.method(parsed.Methods[name])
+		if err != nil {
+			return nil, err
+		}
+		data.Methods = append(data.Methods, method)
+	}
+
+	for path := range g.imports {
+		data.Imports = append(data.Imports, path)
+	}
+	sort.Strings(data.Imports)
+	for _, name := range sortedStructNames(g.structs) {
+		data.Structs = append(data.Structs, g.structs[name])
+	}
+
+	var buf bytes.Buffer
+	if err := contractTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("gen: format generated code error: %w", err)
+	}
+	return source, nil
+}
+
+// extractABI accepts an ABI array or an object carrying it in its "abi" field, e.g. a hardhat artifact
+func extractABI(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var artifact struct {
+			ABI json.RawMessage `json:"abi"`
+		}
+		if err := json.Unmarshal(data, &artifact); err != nil {
+			return nil, fmt.Errorf("gen: parse abi error: %w", err)
+		}
+		if len(artifact.ABI) == 0 {
+			return nil, fmt.Errorf("gen: no abi field found")
+		}
+		data = artifact.ABI
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return nil, fmt.Errorf("gen: parse abi error: %w", err)
+	}
+	return compact.Bytes(), nil
+}
+
+func (g *generator) method(method abi.Method) (methodData, error) {
+	data := methodData{
+		Name:      abi.ToCamelCase(method.Name),
+		ABIName:   method.Name,
+		Signature: method.String(),
+	}
+	used := map[string]bool{"c": true, "callback": true}
+	for i, input := range method.Inputs {
+		typ, err := g.goType(input.Type)
+		if err != nil {
+			return methodData{}, fmt.Errorf("gen: method %s: %w", method.Name, err)
+		}
+		data.Inputs = append(data.Inputs, fieldData{Name: parameterName(input.Name, i, used), Type: typ})
+	}
+
+	if len(method.Outputs) == 1 {
+		typ, err := g.goType(method.Outputs[0].Type)
+		if err != nil {
+			return methodData{}, fmt.Errorf("gen: method %s: %w", method.Name, err)
+		}
+		data.ResultType = typ
+		return data, nil
+	}
+	result := structData{
+		Name: g.typeName + data.Name + "Result",
+		Doc:  fmt.Sprintf("is the result of %s", method.Sig),
+	}
+	for i, output := range method.Outputs {
+		typ, err := g.goType(output.Type)
+		if err != nil {
+			return methodData{}, fmt.Errorf("gen: method %s: %w", method.Name, err)
+		}
+		result.Fields = append(result.Fields, fieldData{Name: fieldName(output.Name, i), Type: typ})
+	}
+	g.structs[result.Name] = result
+	data.ResultType = result.Name
+	return data, nil
+}
+
+// goType returns the Go type the abi package decodes typ into
+func (g *generator) goType(typ abi.Type) (string, error) {
+	switch typ.T {
+	case abi.IntTy, abi.UintTy:
+		prefix := "int"
+		if typ.T == abi.UintTy {
+			prefix = "uint"
+		}
+		switch typ.Size {
+		case 8, 16, 32, 64:
+			return fmt.Sprintf("%s%d", prefix, typ.Size), nil
+		}
+		g.imports["math/big"] = true
+		return "*big.Int", nil
+	case abi.BoolTy:
+		return "bool", nil
+	case abi.StringTy:
+		return "string", nil
+	case abi.AddressTy:
+		return "common.Address", nil
+	case abi.BytesTy:
+		return "[]byte", nil
+	case abi.FixedBytesTy:
+		return fmt.Sprintf("[%d]byte", typ.Size), nil
+	case abi.FunctionTy:
+		return "[24]byte", nil
+	case abi.SliceTy:
+		elem, err := g.goType(*typ.Elem)
+		return "[]" + elem, err
+	case abi.ArrayTy:
+		elem, err := g.goType(*typ.Elem)
+		return fmt.Sprintf("[%d]%s", typ.Size, elem), err
+	case abi.TupleTy:
+		return g.tuple(typ)
+	}
+	return "", fmt.Errorf("unsupported type %s", typ.String())
+}
+
+// tuple declares the struct of a tuple, named after its solidity struct if the ABI carries the internal type,
+// like abigen does
+func (g *generator) tuple(typ abi.Type) (string, error) {
+	fields := make([]fieldData, len(typ.TupleElems))
+	for i, elem := range typ.TupleElems {
+		elemType, err := g.goType(*elem)
+		if err != nil {
+			return "", err
+		}
+		fields[i] = fieldData{Name: fieldName(typ.TupleRawNames[i], i), Type: elemType}
+	}
+
+	name := abi.ToCamelCase(typ.TupleRawName)
+	if name == "" {
+		name = g.typeName + "Tuple"
+		for _, field := range fields {
+			name += field.Name
+		}
+	}
+	if existing, ok := g.structs[name]; ok && !sameFields(existing.Fields, fields) {
+		return "", fmt.Errorf("conflicting declarations of struct %s", name)
+	}
+	g.structs[name] = structData{
+		Name:   name,
+		Doc:    fmt.Sprintf("is the tuple %s", typ.String()),
+		Fields: fields,
+	}
+	return name, nil
+}
+
+func sameFields(a, b []fieldData) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldName is the exported field name the abi package matches name with, Field<i> if unnamed
+func fieldName(name string, index int) string {
+	if name == "" {
+		return fmt.Sprintf("Field%d", index)
+	}
+	return abi.ToCamelCase(name)
+}
+
+// parameterName is an unexported parameter name unique within used, arg<i> if unnamed
+func parameterName(name string, index int, used map[string]bool) string {
+	if name == "" {
+		name = fmt.Sprintf("arg%d", index)
+	}
+	name = abi.ToCamelCase(name)
+	name = strings.ToLower(name[:1]) + name[1:]
+	for token.IsKeyword(name) || used[name] || isPredeclared(name) {
+		name += "_"
+	}
+	used[name] = true
+	return name
+}
+
+// isPredeclared reports whether name shadows an identifier the generated code uses
+func isPredeclared(name string) bool {
+	switch name {
+	case "abi", "big", "common", "multicall", "strings":
+		return true
+	}
+	return false
+}
+
+func sortedStructNames(structs map[string]structData) []string {
+	names := make([]string, 0, len(structs))
+	for name := range structs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var contractTemplate = template.Must(template.New("contract").Parse(`// Code generated by multicallgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+
+// {{.ABIVar}} is the input ABI used to generate the view calls.
+const {{.ABIVar}} = {{.ABI}}
+
+var {{.MethodsVar}} = func() map[string]abi.Method {
+	parsed, err := abi.JSON(strings.NewReader({{.ABIVar}}))
+	if err != nil {
+		panic(err)
+	}
+	return parsed.Methods
+}()
+
+{{range .Structs}}
+// {{.Name}} {{.Doc}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}}
+{{- end}}
+}
+{{end}}
+
+// {{.Type}} builds the view calls of a {{.Type}} contract
+type {{.Type}} struct {
+	Address common.Address
+}
+
+// New{{.Type}} creates the view calls of the {{.Type}} contract at address
+func New{{.Type}}(address common.Address) *{{.Type}} {
+	return &{{.Type}}{Address: address}
+}
+{{$type := .Type}}{{$methods := .MethodsVar}}
+{{- range .Methods}}
+// {{.Name}} calls {{.Signature}}
+func (c *{{$type}}) {{.Name}}({{range .Inputs}}{{.Name}} {{.Type}}, {{end}}callback func(value {{.ResultType}}, err error)) multicall.ViewCall {
+	return multicall.NewTypedABICall[{{.ResultType}}](c.Address, {{$methods}}[{{printf "%q" .ABIName}}], []interface{}{ {{- range $i, $input := .Inputs}}{{if $i}}, {{end}}{{$input.Name}}{{end -}} }, callback)
+}
+{{end}}`))
@@ -0,0 +1,103 @@
+package gen
+
+import (
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// token_gen_test.go is generated from testdata/token.abi.json, regenerate it with:
+//
+//	go run ./cmd/multicallgen --abi gen/testdata/token.abi.json --pkg gen --type Token --out gen/token_gen_test.go
+func TestGenerate(t *testing.T) {
+	data, err := os.ReadFile("testdata/token.abi.json")
+	require.NoError(t, err)
+	source, err := Generate(Options{ABI: data, Package: "gen", Type: "Token"})
+	require.NoError(t, err)
+	golden, err := os.ReadFile("token_gen_test.go")
+	require.NoError(t, err)
+	require.Equal(t, string(golden), string(source))
+
+	// non constant functions and events are skipped
+	require.NotContains(t, string(source), "Transfer(")
+
+	artifact := `{"contractName":"Token","abi":` + string(data) + `,"bytecode":"0x"}`
+	fromArtifact, err := Generate(Options{ABI: []byte(artifact), Package: "gen", Type: "Token"})
+	require.NoError(t, err)
+	require.Equal(t, string(source), string(fromArtifact))
+
+	_, err = Generate(Options{ABI: data, Package: "gen", Type: "token"})
+	require.Error(t, err)
+	_, err = Generate(Options{ABI: []byte(`{"bytecode":"0x"}`), Package: "gen", Type: "Token"})
+	require.ErrorContains(t, err, "no abi field")
+	_, err = Generate(Options{ABI: []byte(`[{"type":"function","name":"f","inputs":[],"outputs":[{"type":"uint256["}],"stateMutability":"view"}]`), Package: "gen", Type: "Token"})
+	require.ErrorContains(t, err, "parse abi error")
+}
+
+func TestGeneratedCalls(t *testing.T) {
+	token := NewToken(common.HexToAddress("0x01"))
+
+	// overloaded functions are numbered like abigen does
+	call := token.BalanceOf0(common.HexToAddress("0x02"), big.NewInt(3), nil)
+	require.Equal(t, token.Address, call.Target())
+	callData, err := call.CallData()
+	require.NoError(t, err)
+	require.Equal(t, crypto.Keccak256([]byte("balanceOf(address,uint256)"))[:4], callData[:4])
+	require.Equal(t, common.LeftPadBytes([]byte{3}, 32), callData[36:])
+
+	var reserves TokenGetReservesResult
+	call = token.GetReserves(func(value TokenGetReservesResult, err error) {
+		require.NoError(t, err)
+		reserves = value
+	})
+	output, err := tokenMethods["getReserves"].Outputs.Pack(big.NewInt(10), big.NewInt(20), uint32(30))
+	require.NoError(t, err)
+	decodeAndCallback(t, call, output)
+	require.Equal(t, TokenGetReservesResult{Reserve0: big.NewInt(10), Reserve1: big.NewInt(20), BlockTimestampLast: 30}, reserves)
+
+	var positions []TokenPosition
+	call = token.Position(big.NewInt(1), func(value []TokenPosition, err error) {
+		require.NoError(t, err)
+		positions = value
+	})
+	expected := []TokenPosition{
+		{Owner: common.HexToAddress("0x04"), Liquidity: big.NewInt(5), Range: TokenRange{Lower: big.NewInt(-6), Upper: big.NewInt(7)}},
+	}
+	output, err = tokenMethods["position"].Outputs.Pack(expected)
+	require.NoError(t, err)
+	decodeAndCallback(t, call, output)
+	require.Equal(t, expected, positions)
+
+	var decimals uint8
+	call = token.Decimals(func(value uint8, err error) {
+		require.NoError(t, err)
+		decimals = value
+	})
+	decodeAndCallback(t, call, common.LeftPadBytes([]byte{18}, 32))
+	require.Equal(t, uint8(18), decimals)
+}
+
+func TestParameterName(t *testing.T) {
+	used := map[string]bool{"c": true, "callback": true}
+	require.Equal(t, "type_", parameterName("type", 0, used))
+	require.Equal(t, "arg1", parameterName("", 1, used))
+	require.Equal(t, "owner", parameterName("_owner", 2, used))
+	require.Equal(t, "owner_", parameterName("owner", 3, used))
+	require.Equal(t, "c_", parameterName("c", 4, used))
+	require.Equal(t, "big_", parameterName("big", 5, used))
+	require.True(t, strings.HasPrefix(parameterName("Callback", 6, used), "callback_"))
+}
+
+func decodeAndCallback(t *testing.T, call interface {
+	Decode(raw []byte) ([]interface{}, error)
+	Callback() func(err error, returnValues []interface{}) error
+}, output []byte) {
+	returnValues, err := call.Decode(output)
+	require.NoError(t, err)
+	require.NoError(t, call.Callback()(nil, returnValues))
+}
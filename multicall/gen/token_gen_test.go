@@ -0,0 +1,82 @@
+// Code generated by multicallgen. DO NOT EDIT.
+
+package gen
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hawkneo/utils/multicall"
+	"math/big"
+	"strings"
+)
+
+// TokenABI is the input ABI used to generate the view calls.
+const TokenABI = "[{\"inputs\":[],\"name\":\"name\",\"outputs\":[{\"internalType\":\"string\",\"name\":\"\",\"type\":\"string\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"decimals\",\"outputs\":[{\"internalType\":\"uint8\",\"name\":\"\",\"type\":\"uint8\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"balanceOf\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"id\",\"type\":\"uint256\"}],\"name\":\"balanceOf\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"getReserves\",\"outputs\":[{\"internalType\":\"uint112\",\"name\":\"_reserve0\",\"type\":\"uint112\"},{\"internalType\":\"uint112\",\"name\":\"_reserve1\",\"type\":\"uint112\"},{\"internalType\":\"uint32\",\"name\":\"_blockTimestampLast\",\"type\":\"uint32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"type\",\"type\":\"uint256\"}],\"name\":\"position\",\"outputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"},{\"internalType\":\"uint128\",\"name\":\"liquidity\",\"type\":\"uint128\"},{\"components\":[{\"internalType\":\"int24\",\"name\":\"lower\",\"type\":\"int24\"},{\"internalType\":\"int24\",\"name\":\"upper\",\"type\":\"int24\"}],\"internalType\":\"struct Token.Range\",\"name\":\"range\",\"type\":\"tuple\"}],\"internalType\":\"struct Token.Position[]\",\"name\":\"\",\"type\":\"tuple[]\"}],\"stateMutability\":\"pure\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"transfer\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"from\",\"type\":\"address\"}],\"name\":\"Transfer\",\"type\":\"event\"}]"
+
+var tokenMethods = func() map[string]abi.Method {
+	parsed, err := abi.JSON(strings.NewReader(TokenABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed.Methods
+}()
+
+// TokenGetReservesResult is the result of getReserves()
+type TokenGetReservesResult struct {
+	Reserve0           *big.Int
+	Reserve1           *big.Int
+	BlockTimestampLast uint32
+}
+
+// TokenPosition is the tuple (address,uint128,(int24,int24))
+type TokenPosition struct {
+	Owner     common.Address
+	Liquidity *big.Int
+	Range     TokenRange
+}
+
+// TokenRange is the tuple (int24,int24)
+type TokenRange struct {
+	Lower *big.Int
+	Upper *big.Int
+}
+
+// Token builds the view calls of a Token contract
+type Token struct {
+	Address common.Address
+}
+
+// NewToken creates the view calls of the Token contract at address
+func NewToken(address common.Address) *Token {
+	return &Token{Address: address}
+}
+
+// BalanceOf calls function balanceOf(address account) view returns(uint256)
+func (c *Token) BalanceOf(account common.Address, callback func(value *big.Int, err error)) multicall.ViewCall {
+	return multicall.NewTypedABICall[*big.Int](c.Address, tokenMethods["balanceOf"], []interface{}{account}, callback)
+}
+
+// BalanceOf0 calls function balanceOf(address account, uint256 id) view returns(uint256)
+func (c *Token) BalanceOf0(account common.Address, id *big.Int, callback func(value *big.Int, err error)) multicall.ViewCall {
+	return multicall.NewTypedABICall[*big.Int](c.Address, tokenMethods["balanceOf0"], []interface{}{account, id}, callback)
+}
+
+// Decimals calls function decimals() view returns(uint8)
+func (c *Token) Decimals(callback func(value uint8, err error)) multicall.ViewCall {
+	return multicall.NewTypedABICall[uint8](c.Address, tokenMethods["decimals"], []interface{}{}, callback)
+}
+
+// GetReserves calls function getReserves() view returns(uint112 _reserve0, uint112 _reserve1, uint32 _blockTimestampLast)
+func (c *Token) GetReserves(callback func(value TokenGetReservesResult, err error)) multicall.ViewCall {
+	return multicall.NewTypedABICall[TokenGetReservesResult](c.Address, tokenMethods["getReserves"], []interface{}{}, callback)
+}
+
+// Name calls function name() view returns(string)
+func (c *Token) Name(callback func(value string, err error)) multicall.ViewCall {
+	return multicall.NewTypedABICall[string](c.Address, tokenMethods["name"], []interface{}{}, callback)
+}
+
+// Position calls function position(uint256 type) pure returns((address,uint128,(int24,int24))[])
+func (c *Token) Position(type_ *big.Int, callback func(value []TokenPosition, err error)) multicall.ViewCall {
+	return multicall.NewTypedABICall[[]TokenPosition](c.Address, tokenMethods["position"], []interface{}{type_}, callback)
+}
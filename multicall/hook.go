@@ -0,0 +1,100 @@
+package multicall
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"math/big"
+	"time"
+)
+
+var _ Caller = (*HookedCaller)(nil)
+
+// Hook observes the requests of a HookedCaller
+type Hook interface {
+	// OnRequest is called before the request, the returned context is passed to the Caller
+	// and the other methods, e.g. to carry a tracing span
+	OnRequest(ctx context.Context, request *RequestInfo) context.Context
+	// OnResponse is called after the request
+	OnResponse(ctx context.Context, request *RequestInfo, response *ResponseInfo)
+	// OnCallDecoded is called for every call of a successful request, after its callback
+	OnCallDecoded(ctx context.Context, request *RequestInfo, result *CallResult)
+}
+
+// RequestInfo describes a request of ViewCalls
+type RequestInfo struct {
+	Calls        ViewCalls
+	CallDataSize int      // total calldata bytes of the calls
+	BlockNumber  *big.Int // requested block, nil for the latest block
+}
+
+// ResponseInfo describes the response of a request
+type ResponseInfo struct {
+	Result   *Result // nil if Err is set
+	Err      error
+	Duration time.Duration
+	Failures int    // calls whose CallResult.Error is set
+	GasUsed  uint64 // total gas used by the calls, only returned by AggregateMulticall
+}
+
+// HookedCaller calls the hooks around every request of a Caller
+type HookedCaller struct {
+	caller Caller
+	hooks  []Hook
+}
+
+// NewHookedCaller creates a HookedCaller in front of a Caller, hooks are called in order
+func NewHookedCaller(caller Caller, hooks ...Hook) *HookedCaller {
+	return &HookedCaller{
+		caller: caller,
+		hooks:  hooks,
+	}
+}
+
+func (c *HookedCaller) Call(opts *bind.CallOpts, calls ViewCalls) (*Result, error) {
+	hooked := &bind.CallOpts{}
+	if opts != nil {
+		*hooked = *opts
+	}
+	ctx := hooked.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	request := &RequestInfo{Calls: calls, BlockNumber: hooked.BlockNumber}
+	for _, call := range calls {
+		if data, err := call.CallData(); err == nil {
+			request.CallDataSize += len(data)
+		}
+	}
+	for _, hook := range c.hooks {
+		ctx = hook.OnRequest(ctx, request)
+	}
+	hooked.Context = ctx
+
+	start := time.Now()
+	result, err := c.caller.Call(hooked, calls)
+	response := &ResponseInfo{Result: result, Err: err, Duration: time.Since(start)}
+	if err == nil {
+		for _, callResult := range result.Calls {
+			if callResult.Error != nil {
+				response.Failures++
+			}
+			if callResult.GasUsed != nil {
+				response.GasUsed += callResult.GasUsed.Uint64()
+			}
+		}
+	}
+
+	for _, hook := range c.hooks {
+		hook.OnResponse(ctx, request, response)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for index := range result.Calls {
+		for _, hook := range c.hooks {
+			hook.OnCallDecoded(ctx, request, &result.Calls[index])
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/stretchr/testify/require"
+)
+
+type hookKey struct{}
+
+// recordingHook records the order of the hook calls
+type recordingHook struct {
+	name      string
+	events    *[]string
+	request   *RequestInfo
+	response  *ResponseInfo
+	decoded   []*CallResult
+	contextOK bool
+}
+
+func (h *recordingHook) OnRequest(ctx context.Context, request *RequestInfo) context.Context {
+	*h.events = append(*h.events, h.name+".OnRequest")
+	h.request = request
+	return context.WithValue(ctx, hookKey{}, h.name)
+}
+
+func (h *recordingHook) OnResponse(ctx context.Context, request *RequestInfo, response *ResponseInfo) {
+	*h.events = append(*h.events, h.name+".OnResponse")
+	h.response = response
+	h.contextOK = ctx.Value(hookKey{}) != nil
+}
+
+func (h *recordingHook) OnCallDecoded(ctx context.Context, request *RequestInfo, result *CallResult) {
+	*h.events = append(*h.events, h.name+".OnCallDecoded")
+	h.decoded = append(h.decoded, result)
+}
+
+// contextCaller fails unless the context of opts carries the value of the hook
+type contextCaller struct {
+	fakeCaller
+}
+
+func (c *contextCaller) Call(opts *bind.CallOpts, calls ViewCalls) (*Result, error) {
+	if opts.Context.Value(hookKey{}) != "b" {
+		return nil, errors.New("context of the hook is missing")
+	}
+	result, err := c.fakeCaller.Call(opts, calls)
+	if err != nil {
+		return nil, err
+	}
+	result.Calls[1].Error = errors.New("reverted")
+	result.Calls[0].GasUsed = big.NewInt(21000)
+	result.Calls[1].GasUsed = big.NewInt(100)
+	return result, nil
+}
+
+func TestHookedCaller_Call(t *testing.T) {
+	var events []string
+	a := &recordingHook{name: "a", events: &events}
+	b := &recordingHook{name: "b", events: &events}
+	caller := NewHookedCaller(&contextCaller{}, a, b)
+
+	res, err := caller.Call(&bind.CallOpts{BlockNumber: big.NewInt(5)}, balanceOfCalls(2))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"a.OnRequest", "b.OnRequest",
+		"a.OnResponse", "b.OnResponse",
+		"a.OnCallDecoded", "b.OnCallDecoded",
+		"a.OnCallDecoded", "b.OnCallDecoded",
+	}, events)
+	require.Equal(t, 2*36, a.request.CallDataSize)
+	require.Equal(t, big.NewInt(5), a.request.BlockNumber)
+	require.True(t, b.contextOK)
+	require.Equal(t, 1, a.response.Failures)
+	require.Equal(t, uint64(21100), a.response.GasUsed)
+	require.Equal(t, res, a.response.Result)
+	require.Len(t, b.decoded, 2)
+	require.Same(t, &res.Calls[1], b.decoded[1])
+
+	events = nil
+	caller = NewHookedCaller(&fakeCaller{err: errors.New("timeout")}, a)
+	_, err = caller.Call(nil, balanceOfCalls(2))
+	require.Error(t, err)
+	require.Equal(t, []string{"a.OnRequest", "a.OnResponse"}, events)
+	require.ErrorIs(t, a.response.Err, err)
+}
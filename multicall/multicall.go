@@ -1,13 +1,20 @@
 package multicall
 
 import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/hawkneo/utils/multicall/contract"
+	"math/big"
 )
 
+var _ Caller = (*Multicall)(nil)
+
 type Multicall struct {
-	eth *contract.AggregateMultiCallContractCaller
+	eth     *contract.AggregateMultiCallContractCaller
+	backend bind.ContractCaller
+	address common.Address
+	errors  *abi.ABI
 }
 
 func NewMulticall(eth bind.ContractCaller, addr common.Address) (*Multicall, error) {
@@ -16,28 +23,60 @@ func NewMulticall(eth bind.ContractCaller, addr common.Address) (*Multicall, err
 		return nil, err
 	}
 	return &Multicall{
-		eth: caller,
+		eth:     caller,
+		backend: eth,
+		address: addr,
 	}, nil
 }
 
+// WithErrors returns a copy of mc which also decodes the reverts with the custom errors of every ABI of
+// parsed, e.g. the ABIs of the different targets of the calls
+func (mc Multicall) WithErrors(parsed ...abi.ABI) *Multicall {
+	mc.errors = mergeErrors(mc.errors, parsed...)
+	return &mc
+}
+
 type CallResult struct {
 	Call    ViewCall
 	Raw     []byte
 	Decoded []interface{}
 	Error   error
+	GasUsed *big.Int // only returned by AggregateMulticall
+	// Success is the success flag of the call returned by the contract, Error may also be set
+	// by the decoder or the callback
+	Success bool
 }
 
 type Result struct {
 	BlockNumber uint64
+	BlockHash   common.Hash // read from the header of the block if the backend is a HeaderReader
 	Calls       []CallResult
 }
 
+// Call executes calls with multicall. The block hash is read by a second request if the backend is a HeaderReader.
 func (mc *Multicall) Call(opts *bind.CallOpts, calls ViewCalls) (*Result, error) {
 	resultRaw, err := mc.makeRequest(opts, calls)
 	if err != nil {
 		return nil, err
 	}
-	return calls.decode(resultRaw)
+	result, err := calls.decode(resultRaw, mc.errors)
+	if err != nil {
+		return nil, err
+	}
+	return result, fillBlockHash(mc.backend, opts, result)
+}
+
+// CallWithOptions is like Call, with the block tag and the state and block overrides of options.
+// The backend must be an RPCCaller if any of them is set.
+func (mc *Multicall) CallWithOptions(options *CallOptions, calls ViewCalls) (*Result, error) {
+	return callWithOptions(mc.backend, options, calls, func(backend bind.ContractCaller) (Caller, error) {
+		caller, err := NewMulticall(backend, mc.address)
+		if err != nil {
+			return nil, err
+		}
+		caller.errors = mc.errors
+		return caller, nil
+	})
 }
 
 func (mc *Multicall) makeRequest(opts *bind.CallOpts, calls ViewCalls) (*contract.MultiCallResult, error) {
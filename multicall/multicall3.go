@@ -1,13 +1,21 @@
 package multicall
 
 import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/hawkneo/utils/multicall/contract"
+	"math/big"
 )
 
+var _ Caller = (*Multicall3)(nil)
+
 type Multicall3 struct {
-	eth *contract.Multicall3Caller
+	eth     *contract.Multicall3Caller
+	backend bind.ContractCaller
+	address common.Address
+	errors  *abi.ABI
 }
 
 func NewMulticall3(eth bind.ContractCaller, addr common.Address) (*Multicall3, error) {
@@ -16,20 +24,71 @@ func NewMulticall3(eth bind.ContractCaller, addr common.Address) (*Multicall3, e
 		return nil, err
 	}
 	return &Multicall3{
-		eth: caller,
+		eth:     caller,
+		backend: eth,
+		address: addr,
 	}, nil
 }
 
+// WithErrors returns a copy of mc which also decodes the reverts with the custom errors of every ABI of
+// parsed, e.g. the ABIs of the different targets of the calls
+func (mc Multicall3) WithErrors(parsed ...abi.ABI) *Multicall3 {
+	mc.errors = mergeErrors(mc.errors, parsed...)
+	return &mc
+}
+
+// Call executes calls with aggregate3, so a call which does not allow failure fails the whole call
+// on-chain. The block number is read by a getBlockNumber call appended to the same aggregate3.
+//
+// aggregate3 reverts without the reason of the failed call, so a revert is followed by a second request
+// allowing every failure, and the error names the first failed call which does not allow failure.
+//
+// blockhash() of the block a call runs on is zero, so the block hash is read by a second request with
+// HeaderByNumber if the backend is a HeaderReader, e.g. ethclient.Client or the simulated backend.
 func (mc *Multicall3) Call(opts *bind.CallOpts, calls ViewCalls) (*Result, error) {
-	resultRaw, err := mc.makeRequest(opts, calls)
+	resultRaw, err := mc.makeRequest(opts, calls, false)
+	if err != nil {
+		if !isRevert(err) {
+			return nil, err
+		}
+		resultRaw, retryErr := mc.makeRequest(opts, calls, true)
+		if retryErr != nil {
+			return nil, err
+		}
+		for index, call := range calls {
+			if !resultRaw.ReturnData[index].Success && !call.AllowFailure() {
+				return nil, fmt.Errorf("multicall3: call %d to %s failed: %w", index, call.Target(), DecodeRevert(resultRaw.ReturnData[index].ReturnData, mc.errors))
+			}
+		}
+		return nil, err
+	}
+	result, err := calls.decode3(resultRaw, mc.errors)
 	if err != nil {
 		return nil, err
 	}
-	return calls.decode3(resultRaw)
+	return result, fillBlockHash(mc.backend, opts, result)
 }
 
-func (mc *Multicall3) makeRequest(opts *bind.CallOpts, calls ViewCalls) (*contract.MultiCall3Result, error) {
-	callDatas := make([]contract.Multicall3Call3, 0)
+// CallWithOptions is like Call, with the block tag and the state and block overrides of options.
+// The backend must be an RPCCaller if any of them is set.
+func (mc *Multicall3) CallWithOptions(options *CallOptions, calls ViewCalls) (*Result, error) {
+	return callWithOptions(mc.backend, options, calls, func(backend bind.ContractCaller) (Caller, error) {
+		caller, err := NewMulticall3(backend, mc.address)
+		if err != nil {
+			return nil, err
+		}
+		caller.errors = mc.errors
+		return caller, nil
+	})
+}
+
+// makeRequest executes calls with aggregate3, allowFailure overrides the AllowFailure of the calls
+func (mc *Multicall3) makeRequest(opts *bind.CallOpts, calls ViewCalls, allowFailure bool) (*contract.MultiCall3BlockResult, error) {
+	multicall3ABI, err := contract.Multicall3MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	callDatas := make([]contract.Multicall3Call3, 0, len(calls)+1)
 	for _, call := range calls {
 		data, err := call.CallData()
 		if err != nil {
@@ -37,9 +96,28 @@ func (mc *Multicall3) makeRequest(opts *bind.CallOpts, calls ViewCalls) (*contra
 		}
 		callDatas = append(callDatas, contract.Multicall3Call3{
 			Target:       call.Target(),
-			AllowFailure: call.AllowFailure(),
+			AllowFailure: allowFailure || call.AllowFailure(),
 			CallData:     data,
 		})
 	}
-	return mc.eth.Aggregate3Static(opts, callDatas)
+	callDatas = append(callDatas, contract.Multicall3Call3{
+		Target:   mc.address,
+		CallData: multicall3ABI.Methods["getBlockNumber"].ID,
+	})
+
+	resultRaw, err := mc.eth.Aggregate3Static(opts, callDatas)
+	if err != nil {
+		return nil, err
+	}
+	if len(resultRaw.ReturnData) != len(callDatas) {
+		return nil, fmt.Errorf("multicall3: got %d results for %d calls", len(resultRaw.ReturnData), len(callDatas))
+	}
+	blockNumber, err := multicall3ABI.Unpack("getBlockNumber", resultRaw.ReturnData[len(calls)].ReturnData)
+	if err != nil {
+		return nil, fmt.Errorf("multicall3: unpack block number error: %w", err)
+	}
+	return &contract.MultiCall3BlockResult{
+		BlockNumber: blockNumber[0].(*big.Int),
+		ReturnData:  resultRaw.ReturnData[:len(calls)],
+	}, nil
 }
@@ -0,0 +1,174 @@
+// Package multicalltest runs Multicall3, AggregateMulticall and ERC20 contracts in go-ethereum's simulated backend,
+// so ViewCalls can be tested end-to-end without network.
+package multicalltest
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/hawkneo/utils/multicall"
+	"github.com/hawkneo/utils/multicall/multicalltest/contract"
+	"math/big"
+	"sort"
+)
+
+const (
+	DefaultAccounts = 3
+	DefaultGasLimit = 30_000_000
+)
+
+var (
+	// Multicall3Address is where Multicall3 is deployed on mainnet and in the Backend
+	Multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+	// AggregateMulticallAddress is where AggregateMulticall is deployed in the Backend
+	AggregateMulticallAddress = common.HexToAddress("0x000000000000000000000000000000000000Ca11")
+
+	// DefaultBalance is the ether balance of every account
+	DefaultBalance = new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+)
+
+// Account is a funded account of the Backend
+type Account struct {
+	Key     *ecdsa.PrivateKey
+	Address common.Address
+}
+
+type BackendOption func(*backendConfig)
+
+type backendConfig struct {
+	accounts int
+	gasLimit uint64
+	alloc    core.GenesisAlloc
+}
+
+// WithAccounts sets the number of funded accounts
+func WithAccounts(accounts int) BackendOption {
+	return func(c *backendConfig) {
+		c.accounts = accounts
+	}
+}
+
+// WithGasLimit sets the block gas limit
+func WithGasLimit(gasLimit uint64) BackendOption {
+	return func(c *backendConfig) {
+		c.gasLimit = gasLimit
+	}
+}
+
+// WithGenesisAlloc adds accounts to the genesis block
+func WithGenesisAlloc(alloc core.GenesisAlloc) BackendOption {
+	return func(c *backendConfig) {
+		for address, account := range alloc {
+			c.alloc[address] = account
+		}
+	}
+}
+
+// Backend is a simulated chain with Multicall3 at Multicall3Address, AggregateMulticall at
+// AggregateMulticallAddress, and funded Accounts.
+type Backend struct {
+	*backends.SimulatedBackend
+	Accounts []Account
+}
+
+func NewBackend(opts ...BackendOption) (*Backend, error) {
+	// the runtime code of Multicall3 is the one deployed by its creation code, like on mainnet
+	multicall3Code, _, _, err := runtime.Create(common.FromHex(contract.Multicall3MetaData.Bin), &runtime.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("multicalltest: create Multicall3 error: %w", err)
+	}
+	aggregateMulticallCode, err := contract.AggregateMulticallCode()
+	if err != nil {
+		return nil, fmt.Errorf("multicalltest: %w", err)
+	}
+	config := &backendConfig{
+		accounts: DefaultAccounts,
+		gasLimit: DefaultGasLimit,
+		alloc: core.GenesisAlloc{
+			Multicall3Address:         {Code: multicall3Code, Balance: new(big.Int)},
+			AggregateMulticallAddress: {Code: aggregateMulticallCode, Balance: new(big.Int)},
+		},
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	b := &Backend{Accounts: make([]Account, config.accounts)}
+	for i := range b.Accounts {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		b.Accounts[i] = Account{Key: key, Address: crypto.PubkeyToAddress(key.PublicKey)}
+		config.alloc[b.Accounts[i].Address] = core.GenesisAccount{Balance: DefaultBalance}
+	}
+	b.SimulatedBackend = backends.NewSimulatedBackend(config.alloc, config.gasLimit)
+	return b, nil
+}
+
+// Multicall3 returns a Multicall3 bound to the deployed contract
+func (b *Backend) Multicall3() (*multicall.Multicall3, error) {
+	return multicall.NewMulticall3(b, Multicall3Address)
+}
+
+// Multicall returns a Multicall bound to the deployed AggregateMulticall
+func (b *Backend) Multicall() (*multicall.Multicall, error) {
+	return multicall.NewMulticall(b, AggregateMulticallAddress)
+}
+
+// TransactOpts returns the transact options of the account at index
+func (b *Backend) TransactOpts(index int) (*bind.TransactOpts, error) {
+	return bind.NewKeyedTransactorWithChainID(b.Accounts[index].Key, b.Blockchain().Config().ChainID)
+}
+
+// DeployERC20 deploys an ERC20 with the initial balances and mines it in one block.
+//
+// The ERC20 is OpenZeppelin's, the first account can mint tokens with its Mint method.
+func (b *Backend) DeployERC20(name, symbol string, decimals uint8, balances map[common.Address]*big.Int) (common.Address, error) {
+	opts, err := b.TransactOpts(0)
+	if err != nil {
+		return common.Address{}, err
+	}
+	address, tx, token, err := contract.DeployERC20(opts, b, b.Accounts[0].Address, common.Address{}, name, symbol, decimals)
+	if err != nil {
+		return common.Address{}, err
+	}
+	txs := []*types.Transaction{tx}
+
+	owners := make([]common.Address, 0, len(balances))
+	for owner := range balances {
+		owners = append(owners, owner)
+	}
+	// deterministic nonces
+	sort.Slice(owners, func(i, j int) bool {
+		return bytes.Compare(owners[i].Bytes(), owners[j].Bytes()) < 0
+	})
+	for _, owner := range owners {
+		tx, err := token.Mint(opts, owner, balances[owner])
+		if err != nil {
+			return common.Address{}, err
+		}
+		txs = append(txs, tx)
+	}
+	b.Commit()
+
+	for _, tx := range txs {
+		receipt, err := b.TransactionReceipt(context.Background(), tx.Hash())
+		if err != nil {
+			return common.Address{}, err
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return common.Address{}, fmt.Errorf("multicalltest: deploy ERC20 transaction %s failed", tx.Hash())
+		}
+	}
+	return address, nil
+}
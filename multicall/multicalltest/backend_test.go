@@ -0,0 +1,274 @@
+package multicalltest
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/hawkneo/utils/multicall"
+	"github.com/hawkneo/utils/multicall/multicalltest/contract"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBackend(t *testing.T) (*Backend, common.Address) {
+	backend, err := NewBackend()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = backend.Close() })
+	token, err := backend.DeployERC20("Test Token", "TT", 6, map[common.Address]*big.Int{
+		backend.Accounts[0].Address: big.NewInt(1_000_000),
+		backend.Accounts[1].Address: big.NewInt(5),
+	})
+	require.NoError(t, err)
+	return backend, token
+}
+
+func erc20Calls(token, owner common.Address) multicall.ViewCalls {
+	return multicall.ViewCalls{
+		multicall.NewViewCall(token, "name()(string)", []interface{}{}, nil),
+		multicall.NewViewCall(token, "symbol()(string)", []interface{}{}, nil),
+		multicall.NewViewCall(token, "decimals()(uint8)", []interface{}{}, nil),
+		multicall.NewViewCall(token, "totalSupply()(uint256)", []interface{}{}, nil),
+		multicall.NewViewCall(token, "balanceOf(address)(uint256)", []interface{}{owner}, nil),
+	}
+}
+
+func requireERC20Results(t *testing.T, calls []multicall.CallResult, balance int64) {
+	for _, call := range calls {
+		require.NoError(t, call.Error)
+	}
+	require.Equal(t, "Test Token", calls[0].Decoded[0])
+	require.Equal(t, "TT", calls[1].Decoded[0])
+	require.Equal(t, uint8(6), calls[2].Decoded[0])
+	require.Equal(t, big.NewInt(1_000_005), calls[3].Decoded[0])
+	require.Equal(t, big.NewInt(balance), calls[4].Decoded[0])
+}
+
+func TestBackend_Multicall3(t *testing.T) {
+	backend, token := newTestBackend(t)
+	owner := backend.Accounts[0].Address
+
+	caller, err := multicall.NewCaller(context.Background(), backend, Multicall3Address)
+	require.NoError(t, err)
+	require.IsType(t, &multicall.Multicall3{}, caller)
+
+	calls := append(erc20Calls(token, owner),
+		multicall.NewViewCall(Multicall3Address, "getEthBalance(address)(uint256)", []interface{}{owner}, nil),
+		// Multicall3 holds no tokens
+		multicall.NewViewCallWithAllowFailure(token, "transfer(address,uint256)(bool)", []interface{}{owner, big.NewInt(1)}, nil, true),
+	)
+	res, err := caller.Call(nil, calls)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), res.BlockNumber)
+	header, err := backend.HeaderByNumber(context.Background(), big.NewInt(1))
+	require.NoError(t, err)
+	require.NotEqual(t, common.Hash{}, res.BlockHash)
+	require.Equal(t, header.Hash(), res.BlockHash)
+	requireERC20Results(t, res.Calls[:5], 1_000_000)
+	require.NoError(t, res.Calls[5].Error)
+	require.Positive(t, res.Calls[5].Decoded[0].(*big.Int).Sign())
+
+	var revertErr *multicall.RevertError
+	require.True(t, errors.As(res.Calls[6].Error, &revertErr))
+	require.Equal(t, "ERC20: transfer amount exceeds balance", revertErr.Reason)
+
+	// a failed call which does not allow failure fails the whole call
+	_, err = caller.Call(nil, multicall.ViewCalls{
+		multicall.NewViewCall(token, "transfer(address,uint256)(bool)", []interface{}{owner, big.NewInt(1)}, nil),
+	})
+	require.True(t, errors.As(err, &revertErr))
+	require.Equal(t, "ERC20: transfer amount exceeds balance", revertErr.Reason)
+}
+
+func TestBackend_Multicall(t *testing.T) {
+	backend, token := newTestBackend(t)
+	owner := backend.Accounts[0].Address
+
+	caller, err := multicall.NewCaller(context.Background(), backend, AggregateMulticallAddress)
+	require.NoError(t, err)
+	require.IsType(t, &multicall.Multicall{}, caller)
+
+	gasLimit := big.NewInt(100_000)
+	calls := multicall.ViewCalls{
+		multicall.NewViewCallWithGasLimit(token, "name()(string)", []interface{}{}, nil, gasLimit),
+		multicall.NewViewCallWithGasLimit(token, "balanceOf(address)(uint256)", []interface{}{owner}, nil, gasLimit),
+		multicall.NewViewCallWithGasLimit(AggregateMulticallAddress, "getEthBalance(address)(uint256)", []interface{}{owner}, nil, gasLimit),
+		multicall.NewViewCallWithGasLimit(AggregateMulticallAddress, "getCurrentBlockTimestamp()(uint256)", []interface{}{}, nil, gasLimit),
+		// AggregateMulticall holds no tokens
+		multicall.NewViewCallWithGasLimit(token, "transfer(address,uint256)(bool)", []interface{}{owner, big.NewInt(1)}, nil, gasLimit),
+		// out of gas
+		multicall.NewViewCallWithGasLimit(token, "name()(string)", []interface{}{}, nil, big.NewInt(100)),
+	}
+	res, err := caller.Call(nil, calls)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), res.BlockNumber)
+	header, err := backend.HeaderByNumber(context.Background(), big.NewInt(1))
+	require.NoError(t, err)
+	require.Equal(t, header.Hash(), res.BlockHash)
+
+	require.NoError(t, res.Calls[0].Error)
+	require.Equal(t, "Test Token", res.Calls[0].Decoded[0])
+	require.NoError(t, res.Calls[1].Error)
+	require.Equal(t, big.NewInt(1_000_000), res.Calls[1].Decoded[0])
+	require.NoError(t, res.Calls[2].Error)
+	require.Positive(t, res.Calls[2].Decoded[0].(*big.Int).Sign())
+	require.NoError(t, res.Calls[3].Error)
+	require.Equal(t, new(big.Int).SetUint64(header.Time), res.Calls[3].Decoded[0])
+	for _, call := range res.Calls[:4] {
+		require.True(t, call.Success)
+		require.Positive(t, call.GasUsed.Sign())
+		require.True(t, call.GasUsed.Cmp(gasLimit) < 0)
+	}
+
+	var revertErr *multicall.RevertError
+	require.False(t, res.Calls[4].Success)
+	require.True(t, errors.As(res.Calls[4].Error, &revertErr))
+	require.Equal(t, "ERC20: transfer amount exceeds balance", revertErr.Reason)
+	require.Equal(t, gasLimit, revertErr.GasLimit)
+	require.Positive(t, revertErr.GasUsed.Sign())
+
+	require.False(t, res.Calls[5].Success)
+	require.True(t, errors.As(res.Calls[5].Error, &revertErr))
+	require.Empty(t, res.Calls[5].Raw)
+	require.True(t, revertErr.GasUsed.Cmp(big.NewInt(100)) >= 0)
+}
+
+func TestBackend_Batcher(t *testing.T) {
+	backend, token := newTestBackend(t)
+	mc, err := backend.Multicall3()
+	require.NoError(t, err)
+
+	calls := make(multicall.ViewCalls, 0)
+	for _, account := range backend.Accounts {
+		calls = append(calls, multicall.NewViewCall(token, "balanceOf(address)(uint256)", []interface{}{account.Address}, nil))
+	}
+	res, err := multicall.NewBatcher(mc, multicall.WithMaxCalls(1), multicall.WithHeaderReader(backend)).Call(nil, calls)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), res.BlockNumber)
+	require.Equal(t, big.NewInt(1_000_000), res.Calls[0].Decoded[0])
+	require.Equal(t, big.NewInt(5), res.Calls[1].Decoded[0])
+	require.Zero(t, res.Calls[2].Decoded[0].(*big.Int).Sign())
+}
+
+func TestBackend_ERC20Transactions(t *testing.T) {
+	backend, token := newTestBackend(t)
+	erc20, err := contract.NewERC20(token, backend)
+	require.NoError(t, err)
+	alice, bob, carol := backend.Accounts[0].Address, backend.Accounts[1].Address, backend.Accounts[2].Address
+
+	aliceOpts, err := backend.TransactOpts(0)
+	require.NoError(t, err)
+	bobOpts, err := backend.TransactOpts(1)
+	require.NoError(t, err)
+	_, err = erc20.Transfer(aliceOpts, bob, big.NewInt(100))
+	require.NoError(t, err)
+	_, err = erc20.Approve(aliceOpts, bob, big.NewInt(50))
+	require.NoError(t, err)
+	backend.Commit()
+	tx, err := erc20.TransferFrom(bobOpts, alice, carol, big.NewInt(30))
+	require.NoError(t, err)
+	backend.Commit()
+
+	receipt, err := bind.WaitMined(nil, backend, tx)
+	require.NoError(t, err)
+	require.Len(t, receipt.Logs, 2)
+	transfer, err := erc20.ParseTransfer(*receipt.Logs[1])
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(30), transfer.Value)
+
+	mc, err := backend.Multicall3()
+	require.NoError(t, err)
+	res, err := mc.Call(nil, multicall.ViewCalls{
+		multicall.NewViewCall(token, "balanceOf(address)(uint256)", []interface{}{alice}, nil),
+		multicall.NewViewCall(token, "balanceOf(address)(uint256)", []interface{}{bob}, nil),
+		multicall.NewViewCall(token, "balanceOf(address)(uint256)", []interface{}{carol}, nil),
+		multicall.NewViewCall(token, "allowance(address,address)(uint256)", []interface{}{alice, bob}, nil),
+	})
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1_000_000-130), res.Calls[0].Decoded[0])
+	require.Equal(t, big.NewInt(105), res.Calls[1].Decoded[0])
+	require.Equal(t, big.NewInt(30), res.Calls[2].Decoded[0])
+	require.Equal(t, big.NewInt(20), res.Calls[3].Decoded[0])
+
+	// more than the allowance
+	_, err = erc20.TransferFrom(bobOpts, alice, carol, big.NewInt(21))
+	require.ErrorContains(t, err, "ERC20: insufficient allowance")
+}
+
+func TestBackend_BuildTransaction(t *testing.T) {
+	backend, err := NewBackend()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = backend.Close() })
+	token, err := backend.DeployERC20("Test Token", "TT", 6, map[common.Address]*big.Int{
+		Multicall3Address: big.NewInt(100),
+	})
+	require.NoError(t, err)
+	alice, bob, carol := backend.Accounts[0].Address, backend.Accounts[1].Address, backend.Accounts[2].Address
+
+	transfer, err := multicall.NewWriteCall(token, "transfer(address,uint256)", []interface{}{bob, big.NewInt(60)}, nil, false)
+	require.NoError(t, err)
+	overdraw, err := multicall.NewWriteCall(token, "transfer(address,uint256)", []interface{}{bob, big.NewInt(60)}, nil, true)
+	require.NoError(t, err)
+	calls := []multicall.WriteCall{
+		transfer,
+		{Target: carol, Value: big.NewInt(1e18)},
+		overdraw,
+	}
+
+	mc, err := backend.Multicall3()
+	require.NoError(t, err)
+	tx, _, err := mc.BuildTransaction(&bind.TransactOpts{From: alice}, calls)
+	require.NoError(t, err)
+	require.Equal(t, Multicall3Address, *tx.To())
+	require.Equal(t, big.NewInt(1e18), tx.Value())
+	require.Positive(t, tx.Gas())
+	v, r, s := tx.RawSignatureValues()
+	require.Zero(t, v.Sign()+r.Sign()+s.Sign())
+
+	opts, err := backend.TransactOpts(0)
+	require.NoError(t, err)
+	signed, err := opts.Signer(alice, tx)
+	require.NoError(t, err)
+	require.NoError(t, backend.SendTransaction(context.Background(), signed))
+	backend.Commit()
+	receipt, err := bind.WaitMined(context.Background(), backend, signed)
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+
+	res, err := mc.Call(nil, multicall.ViewCalls{
+		multicall.NewViewCall(token, "balanceOf(address)(uint256)", []interface{}{bob}, nil),
+		multicall.NewViewCall(Multicall3Address, "getEthBalance(address)(uint256)", []interface{}{carol}, nil),
+	})
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(60), res.Calls[0].Decoded[0])
+	require.Equal(t, new(big.Int).Add(DefaultBalance, big.NewInt(1e18)), res.Calls[1].Decoded[0])
+
+	// a call which does not allow failure reverts, the gas estimation fails
+	calls[2].AllowFailure = false
+	_, _, err = mc.BuildTransaction(&bind.TransactOpts{From: alice}, calls)
+	require.ErrorContains(t, err, "Multicall3: call failed")
+}
+
+func TestBackend_Executor(t *testing.T) {
+	backend, token := newTestBackend(t)
+	mc, err := backend.Multicall3()
+	require.NoError(t, err)
+
+	calls := append(erc20Calls(token, backend.Accounts[0].Address),
+		// Multicall3 holds no tokens, the transfer reverts the whole batch
+		multicall.NewViewCall(token, "transfer(address,uint256)(bool)", []interface{}{backend.Accounts[0].Address, big.NewInt(1)}, nil),
+	)
+	_, err = mc.Call(nil, calls)
+	require.Error(t, err)
+
+	res, err := multicall.NewExecutor(mc).Call(nil, calls)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), res.BlockNumber)
+	requireERC20Results(t, res.Calls[:5], 1_000_000)
+	var revertErr *multicall.RevertError
+	require.True(t, errors.As(res.Calls[5].Error, &revertErr))
+	require.Equal(t, "ERC20: transfer amount exceeds balance", revertErr.Reason)
+}
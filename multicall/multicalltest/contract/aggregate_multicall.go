@@ -0,0 +1,25 @@
+package contract
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/asm"
+)
+
+//go:embed aggregate_multicall.easm
+var aggregateMulticallSource []byte
+
+// AggregateMulticallCode compiles the runtime code of AggregateMulticall from aggregate_multicall.easm.
+//
+// The Solidity contract's bytecode is not published, the runtime is written for the simulated backend
+// with the same ABI and behavior.
+func AggregateMulticallCode() ([]byte, error) {
+	compiler := asm.NewCompiler(false)
+	compiler.Feed(asm.Lex(aggregateMulticallSource, false))
+	code, errs := compiler.Compile()
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("compile AggregateMulticall error: %w", errs[0])
+	}
+	return common.FromHex(code), nil
+}
@@ -0,0 +1,33 @@
+// Package contract holds the bindings of the contracts deployed by multicalltest, generated with their
+// creation bytecode, which the bindings in multicall/contract do not have.
+//
+// multicall3.abi and multicall3.bin are the ABI and Bin of op-e2e/bindings/multicall3.go in
+// github.com/ethereum-optimism/optimism v1.9.3: Multicall3 of github.com/mds1/multicall, compiled with solc 0.8.12
+// according to the metadata at the end of the bytecode. The runtime code it deploys is Preinstalls.MultiCall3Code
+// of packages/contracts-bedrock/src/libraries/Preinstalls.sol in the same tag, the code at
+// 0xcA11bde05977b3631167028862bE2a173976CA11 on mainnet.
+//
+// erc20.abi and erc20.bin are the ABI and Bin of op-e2e/bindings/optimismmintableerc20.go in the same tag:
+// packages/contracts-bedrock/src/universal/OptimismMintableERC20.sol, an OpenZeppelin ERC20 with a configurable
+// decimals whose bridge can mint tokens, compiled with solc 0.8.15 according to its metadata, with the settings
+// of packages/contracts-bedrock/foundry.toml.
+//
+// The .bin files are the Bin strings without the 0x prefix and without a trailing newline, their sha256 are
+//
+//	1f916570bb2e0c6187cfd62903e602c14b56ea2c88da5e0c9535940e782dfabd  multicall3.bin
+//	b44fbc59a40895fc525d9943c3fe4dcfe2064038a2cb6e4644ba799bac1103a7  erc20.bin
+//
+// and they can be checked against the module of the tag with
+//
+//	zip=$(go mod download -json github.com/ethereum-optimism/optimism@v1.9.3 | jq -r .Zip)
+//	unzip -p $zip github.com/ethereum-optimism/optimism@v1.9.3/op-e2e/bindings/multicall3.go |
+//		grep -o 'Bin: "0x[0-9a-f]*"' | sed 's/Bin: "0x//;s/"//' | tr -d '\n' | sha256sum
+//
+// and the same with optimismmintableerc20.go.
+//
+// The bytecode of AggregateMulticall is not published, aggregate_multicall.easm is a runtime code with its ABI,
+// compiled with go-ethereum's core/asm by AggregateMulticallCode.
+package contract
+
+//go:generate abigen --abi multicall3.abi --bin multicall3.bin --pkg contract --type Multicall3 --out multicall3.go
+//go:generate abigen --abi erc20.abi --bin erc20.bin --pkg contract --type ERC20 --out erc20.go
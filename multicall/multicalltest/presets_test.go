@@ -0,0 +1,49 @@
+package multicalltest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hawkneo/utils/math/decimal"
+	"github.com/hawkneo/utils/multicall"
+	"github.com/hawkneo/utils/multicall/presets"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresets_ERC20(t *testing.T) {
+	backend, err := NewBackend()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = backend.Close() })
+	owner, spender := backend.Accounts[0].Address, backend.Accounts[1].Address
+	token, err := backend.DeployERC20("Test Token", "TT", 6, map[common.Address]*big.Int{
+		owner: big.NewInt(1_234_567),
+	})
+	require.NoError(t, err)
+	mc, err := backend.Multicall3()
+	require.NoError(t, err)
+
+	var (
+		name, symbol                    string
+		decimals                        uint8
+		totalSupply, balance, allowance decimal.Decimal
+		ethBalance                      decimal.Decimal
+	)
+	_, err = mc.Call(nil, multicall.ViewCalls{
+		presets.ERC20Name(token, func(value string, err error) { require.NoError(t, err); name = value }),
+		presets.ERC20Symbol(token, func(value string, err error) { require.NoError(t, err); symbol = value }),
+		presets.ERC20Decimals(token, func(value uint8, err error) { require.NoError(t, err); decimals = value }),
+		presets.ERC20TotalSupply(token, 6, func(value decimal.Decimal, err error) { require.NoError(t, err); totalSupply = value }),
+		presets.ERC20BalanceOf(token, owner, 6, func(value decimal.Decimal, err error) { require.NoError(t, err); balance = value }),
+		presets.ERC20Allowance(token, owner, spender, 6, func(value decimal.Decimal, err error) { require.NoError(t, err); allowance = value }),
+		presets.EthBalance(Multicall3Address, spender, func(value decimal.Decimal, err error) { require.NoError(t, err); ethBalance = value }),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Test Token", name)
+	require.Equal(t, "TT", symbol)
+	require.Equal(t, uint8(6), decimals)
+	require.Equal(t, "1.234567", totalSupply.String())
+	require.Equal(t, "1.234567", balance.String())
+	require.True(t, allowance.IsZero())
+	require.Equal(t, "1000", ethBalance.StripTrailingZeros().String())
+}
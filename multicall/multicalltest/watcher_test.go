@@ -0,0 +1,63 @@
+package multicalltest
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hawkneo/utils/multicall"
+	"github.com/hawkneo/utils/multicall/multicalltest/contract"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	for name, opts := range map[string][]multicall.WatchOption{
+		"subscribe": nil,
+		"poll":      {multicall.WithPollInterval(10 * time.Millisecond)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			backend, token := newTestBackend(t)
+			alice, bob := backend.Accounts[0].Address, backend.Accounts[1].Address
+			mc, err := backend.Multicall3()
+			require.NoError(t, err)
+
+			w := multicall.NewWatcher(mc, backend, multicall.ViewCalls{
+				multicall.NewViewCall(token, "balanceOf(address)(uint256)", []interface{}{bob}, nil),
+			}, opts...)
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+			go func() { done <- w.Run(ctx) }()
+
+			requireResult := func(number uint64, balance int64) {
+				result := <-w.Results()
+				require.NoError(t, result.Err)
+				require.Equal(t, number, result.Header.Number.Uint64())
+				require.Equal(t, number, result.Result.BlockNumber)
+				require.Equal(t, result.Header.Hash(), result.Result.BlockHash)
+				require.Equal(t, big.NewInt(balance), result.Result.Calls[0].Decoded[0])
+			}
+			requireResult(1, 5)
+
+			erc20, err := contract.NewERC20(token, backend)
+			require.NoError(t, err)
+			opts, err := backend.TransactOpts(0)
+			require.NoError(t, err)
+			_, err = erc20.Transfer(opts, bob, big.NewInt(10))
+			require.NoError(t, err)
+			backend.Commit()
+			requireResult(2, 15)
+
+			w.SetCalls(multicall.ViewCalls{
+				multicall.NewViewCall(token, "balanceOf(address)(uint256)", []interface{}{alice}, nil),
+			})
+			backend.Commit()
+			requireResult(3, 1_000_000-10)
+
+			cancel()
+			require.ErrorIs(t, <-done, context.Canceled)
+			_, ok := <-w.Results()
+			require.False(t, ok)
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package multicall
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+)
+
+var (
+	_ bind.ContractCaller = (*RPCCaller)(nil)
+	_ HeaderReader        = (*RPCCaller)(nil)
+
+	ErrCallOptionsUnsupported = errors.New("multicall: block tags and overrides require an RPCCaller backend")
+)
+
+// StateOverride replaces the balance, nonce, code or storage of accounts during an eth_call
+type StateOverride map[common.Address]OverrideAccount
+
+// OverrideAccount is the state of an account during an eth_call, nil fields are not overridden.
+// State replaces the whole storage, StateDiff only the given slots.
+type OverrideAccount struct {
+	Nonce     *uint64
+	Code      []byte
+	Balance   *big.Int
+	State     map[common.Hash]common.Hash
+	StateDiff map[common.Hash]common.Hash
+}
+
+func (a OverrideAccount) MarshalJSON() ([]byte, error) {
+	type account struct {
+		Nonce     *hexutil.Uint64             `json:"nonce,omitempty"`
+		Code      *hexutil.Bytes              `json:"code,omitempty"`
+		Balance   *hexutil.Big                `json:"balance,omitempty"`
+		State     map[common.Hash]common.Hash `json:"state,omitempty"`
+		StateDiff map[common.Hash]common.Hash `json:"stateDiff,omitempty"`
+	}
+	return json.Marshal(account{
+		Nonce:     (*hexutil.Uint64)(a.Nonce),
+		Code:      (*hexutil.Bytes)(codeOrNil(a.Code)),
+		Balance:   (*hexutil.Big)(a.Balance),
+		State:     a.State,
+		StateDiff: a.StateDiff,
+	})
+}
+
+// BlockOverrides replaces the fields of the block an eth_call is executed in,
+// nil fields are not overridden.
+type BlockOverrides struct {
+	Number     *big.Int
+	Difficulty *big.Int
+	Time       *uint64
+	GasLimit   *uint64
+	Coinbase   *common.Address
+	Random     *common.Hash
+	BaseFee    *big.Int
+}
+
+func (o BlockOverrides) MarshalJSON() ([]byte, error) {
+	type overrides struct {
+		Number     *hexutil.Big    `json:"number,omitempty"`
+		Difficulty *hexutil.Big    `json:"difficulty,omitempty"`
+		Time       *hexutil.Uint64 `json:"time,omitempty"`
+		GasLimit   *hexutil.Uint64 `json:"gasLimit,omitempty"`
+		Coinbase   *common.Address `json:"coinbase,omitempty"`
+		Random     *common.Hash    `json:"random,omitempty"`
+		BaseFee    *hexutil.Big    `json:"baseFee,omitempty"`
+	}
+	return json.Marshal(overrides{
+		Number:     (*hexutil.Big)(o.Number),
+		Difficulty: (*hexutil.Big)(o.Difficulty),
+		Time:       (*hexutil.Uint64)(o.Time),
+		GasLimit:   (*hexutil.Uint64)(o.GasLimit),
+		Coinbase:   o.Coinbase,
+		Random:     o.Random,
+		BaseFee:    (*hexutil.Big)(o.BaseFee),
+	})
+}
+
+// CallOptions extends bind.CallOpts with the eth_call options it can't express.
+//
+// BlockTag selects the block by number or tag, e.g. rpc.SafeBlockNumber or rpc.EarliestBlockNumber,
+// it can't be combined with CallOpts.BlockNumber or CallOpts.Pending.
+type CallOptions struct {
+	*bind.CallOpts
+	BlockTag       *rpc.BlockNumber
+	StateOverride  StateOverride
+	BlockOverrides *BlockOverrides
+}
+
+func (o *CallOptions) rpcOnly() bool {
+	return o.BlockTag != nil || len(o.StateOverride) > 0 || o.BlockOverrides != nil
+}
+
+// bindOpts returns the bind.CallOpts of the options, it fails if the block is selected twice
+func (o *CallOptions) bindOpts() (*bind.CallOpts, error) {
+	if o.CallOpts == nil {
+		return nil, nil
+	}
+	if o.BlockTag != nil && (o.BlockNumber != nil || o.Pending) {
+		return nil, errors.New("multicall: BlockTag can't be combined with BlockNumber or Pending")
+	}
+	return o.CallOpts, nil
+}
+
+// callWithOptions calls the caller created by newCaller on a backend applying options,
+// backend must be an RPCCaller if any of them is set. nil options are empty options.
+func callWithOptions(backend bind.ContractCaller, options *CallOptions, calls ViewCalls, newCaller func(bind.ContractCaller) (Caller, error)) (*Result, error) {
+	if options == nil {
+		options = &CallOptions{}
+	}
+	opts, err := options.bindOpts()
+	if err != nil {
+		return nil, err
+	}
+	if rpcCaller, ok := backend.(*RPCCaller); ok {
+		backend = rpcCaller.WithOptions(options)
+	} else if options.rpcOnly() {
+		return nil, ErrCallOptionsUnsupported
+	}
+	caller, err := newCaller(backend)
+	if err != nil {
+		return nil, err
+	}
+	return caller.Call(opts, calls)
+}
+
+// RPCCaller is a bind.ContractCaller on top of an rpc.Client, which applies the block
+// tag and overrides of CallOptions to eth_call.
+//
+// Negative block numbers are block tags as defined by rpc.BlockNumber.
+type RPCCaller struct {
+	*ethclient.Client
+	rpc *rpc.Client
+}
+
+func NewRPCCaller(client *rpc.Client) *RPCCaller {
+	return &RPCCaller{
+		Client: ethclient.NewClient(client),
+		rpc:    client,
+	}
+}
+
+func DialRPCCaller(rawurl string) (*RPCCaller, error) {
+	client, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return NewRPCCaller(client), nil
+}
+
+func (c *RPCCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return c.call(ctx, msg, toBlockNumArg(blockNumber), nil)
+}
+
+func (c *RPCCaller) call(ctx context.Context, msg ethereum.CallMsg, block string, options *CallOptions) ([]byte, error) {
+	args := []interface{}{toCallArg(msg), block}
+	if options != nil {
+		if options.BlockOverrides != nil {
+			args = append(args, options.StateOverride, options.BlockOverrides)
+		} else if len(options.StateOverride) > 0 {
+			args = append(args, options.StateOverride)
+		}
+	}
+	var hex hexutil.Bytes
+	if err := c.rpc.CallContext(ctx, &hex, "eth_call", args...); err != nil {
+		return nil, err
+	}
+	return hex, nil
+}
+
+func (c *RPCCaller) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return c.codeAt(ctx, account, toBlockNumArg(blockNumber))
+}
+
+func (c *RPCCaller) codeAt(ctx context.Context, account common.Address, block string) ([]byte, error) {
+	var code hexutil.Bytes
+	if err := c.rpc.CallContext(ctx, &code, "eth_getCode", account, block); err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// WithOptions returns a backend which applies the block tag and the overrides of options to
+// every call, also on the pending state. The block tag is only used by the calls without a
+// block number, so a Batcher in front of it still pins its chunks to the same block.
+// nil options are empty options.
+func (c *RPCCaller) WithOptions(options *CallOptions) bind.ContractCaller {
+	if options == nil {
+		options = &CallOptions{}
+	}
+	return &optionsCaller{caller: c, options: options}
+}
+
+func (c *RPCCaller) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var head *types.Header
+	if err := c.rpc.CallContext(ctx, &head, "eth_getBlockByNumber", toBlockNumArg(number), false); err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return nil, ethereum.NotFound
+	}
+	return head, nil
+}
+
+// TransactionOutput returns the return data of a mined transaction with the callTracer of
+// debug_traceTransaction, the node must expose the debug namespace
+func (c *RPCCaller) TransactionOutput(ctx context.Context, txHash common.Hash) ([]byte, error) {
+	var frame struct {
+		Output hexutil.Bytes `json:"output"`
+		Error  string        `json:"error"`
+	}
+	config := map[string]interface{}{
+		"tracer":       "callTracer",
+		"tracerConfig": map[string]interface{}{"onlyTopCall": true},
+	}
+	if err := c.rpc.CallContext(ctx, &frame, "debug_traceTransaction", txHash, config); err != nil {
+		return nil, err
+	}
+	if frame.Error != "" {
+		return nil, fmt.Errorf("transaction %s failed: %s", txHash, frame.Error)
+	}
+	return frame.Output, nil
+}
+
+// optionsCaller is an RPCCaller with fixed CallOptions
+type optionsCaller struct {
+	caller  *RPCCaller
+	options *CallOptions
+}
+
+var (
+	_ bind.ContractCaller        = (*optionsCaller)(nil)
+	_ bind.PendingContractCaller = (*optionsCaller)(nil)
+	_ HeaderReader               = (*optionsCaller)(nil)
+)
+
+func (c *optionsCaller) block(blockNumber *big.Int) string {
+	if blockNumber == nil && c.options.BlockTag != nil {
+		text, _ := c.options.BlockTag.MarshalText()
+		return string(text)
+	}
+	return toBlockNumArg(blockNumber)
+}
+
+func (c *optionsCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return c.caller.call(ctx, msg, c.block(blockNumber), c.options)
+}
+
+func (c *optionsCaller) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	return c.caller.call(ctx, msg, "pending", c.options)
+}
+
+func (c *optionsCaller) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	if override, ok := c.options.StateOverride[account]; ok && override.Code != nil {
+		return override.Code, nil
+	}
+	return c.caller.codeAt(ctx, account, c.block(blockNumber))
+}
+
+func (c *optionsCaller) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	if override, ok := c.options.StateOverride[account]; ok && override.Code != nil {
+		return override.Code, nil
+	}
+	return c.caller.codeAt(ctx, account, "pending")
+}
+
+// HeaderByNumber reads the header of the block of a call, a block with overrides is not in the chain
+func (c *optionsCaller) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if c.options.BlockOverrides != nil {
+		return nil, ethereum.NotFound
+	}
+	return c.caller.HeaderByNumber(ctx, number)
+}
+
+// codeOrNil keeps an empty but non-nil code, which removes the code of the account
+func codeOrNil(code []byte) *[]byte {
+	if code == nil {
+		return nil
+	}
+	return &code
+}
+
+// toBlockNumArg encodes block numbers like rpc.BlockNumber, unlike ethclient which
+// treats -1 as pending
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	if number.Sign() >= 0 {
+		return hexutil.EncodeBig(number)
+	}
+	text, _ := rpc.BlockNumber(number.Int64()).MarshalText()
+	return string(text)
+}
+
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	return arg
+}
@@ -0,0 +1,164 @@
+package multicall
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/hawkneo/utils/multicall/contract"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEthService records the parameters of eth_call and answers with a fixed output
+type fakeEthService struct {
+	output         hexutil.Bytes
+	block          string
+	stateOverride  json.RawMessage
+	blockOverrides json.RawMessage
+}
+
+func (s *fakeEthService) Call(ctx context.Context, args map[string]interface{}, block string, stateOverride, blockOverrides *json.RawMessage) (hexutil.Bytes, error) {
+	s.block = block
+	s.stateOverride, s.blockOverrides = nil, nil
+	if stateOverride != nil {
+		s.stateOverride = *stateOverride
+	}
+	if blockOverrides != nil {
+		s.blockOverrides = *blockOverrides
+	}
+	return s.output, nil
+}
+
+// GetBlockByNumber answers with a header of the requested block number
+func (s *fakeEthService) GetBlockByNumber(ctx context.Context, number hexutil.Big, full bool) (*types.Header, error) {
+	return &types.Header{Number: (*big.Int)(&number), Difficulty: common.Big0}, nil
+}
+
+func blockTag(number rpc.BlockNumber) *rpc.BlockNumber {
+	return &number
+}
+
+func TestMulticall3_CallWithOptions(t *testing.T) {
+	service := &fakeEthService{output: packAggregate3(t, 9,
+		contract.Multicall3Result{Success: true, ReturnData: common.LeftPadBytes([]byte{5}, 32)},
+	)}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", service))
+	defer server.Stop()
+
+	mc, err := NewMulticall3(NewRPCCaller(rpc.DialInProc(server)), common.Address{})
+	require.NoError(t, err)
+
+	owner := common.HexToAddress("0x01")
+	slot := common.HexToHash("0x02")
+	timestamp := uint64(1700000000)
+	res, err := mc.CallWithOptions(&CallOptions{
+		BlockTag: blockTag(rpc.SafeBlockNumber),
+		StateOverride: StateOverride{
+			owner: {Balance: big.NewInt(1e18), State: map[common.Hash]common.Hash{slot: common.HexToHash("0x05")}},
+		},
+		BlockOverrides: &BlockOverrides{Time: &timestamp},
+	}, balanceOfCalls(1))
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(5), res.Calls[0].Decoded[0])
+	// a block with overrides has no hash
+	require.Equal(t, common.Hash{}, res.BlockHash)
+	require.Equal(t, "safe", service.block)
+	require.JSONEq(t, `{"0x0000000000000000000000000000000000000001":{"balance":"0xde0b6b3a7640000","state":{"0x0000000000000000000000000000000000000000000000000000000000000002":"0x0000000000000000000000000000000000000000000000000000000000000005"}}}`, string(service.stateOverride))
+	require.JSONEq(t, `{"time":"0x6553f100"}`, string(service.blockOverrides))
+
+	_, err = mc.CallWithOptions(&CallOptions{BlockTag: blockTag(rpc.PendingBlockNumber)}, balanceOfCalls(1))
+	require.NoError(t, err)
+	require.Equal(t, "pending", service.block)
+	require.Nil(t, service.stateOverride)
+
+	res, err = mc.CallWithOptions(&CallOptions{StateOverride: StateOverride{owner: OverrideAccount{Code: []byte{}}}}, balanceOfCalls(1))
+	require.NoError(t, err)
+	header := &types.Header{Number: big.NewInt(9), Difficulty: common.Big0}
+	require.Equal(t, header.Hash(), res.BlockHash)
+	require.Equal(t, "latest", service.block)
+	require.JSONEq(t, `{"0x0000000000000000000000000000000000000001":{"code":"0x"}}`, string(service.stateOverride))
+	require.Nil(t, service.blockOverrides)
+
+	// the genesis block is block 0
+	_, err = mc.CallWithOptions(&CallOptions{BlockTag: blockTag(rpc.EarliestBlockNumber)}, balanceOfCalls(1))
+	require.NoError(t, err)
+	require.Equal(t, "earliest", service.block)
+
+	// the overrides are also applied to the pending state
+	_, err = mc.CallWithOptions(&CallOptions{
+		CallOpts:      &bind.CallOpts{Pending: true},
+		StateOverride: StateOverride{owner: {Balance: big.NewInt(1)}},
+	}, balanceOfCalls(1))
+	require.NoError(t, err)
+	require.Equal(t, "pending", service.block)
+	require.JSONEq(t, `{"0x0000000000000000000000000000000000000001":{"balance":"0x1"}}`, string(service.stateOverride))
+
+	_, err = mc.CallWithOptions(&CallOptions{
+		CallOpts: &bind.CallOpts{BlockNumber: big.NewInt(1)},
+		BlockTag: blockTag(rpc.SafeBlockNumber),
+	}, balanceOfCalls(1))
+	require.Error(t, err)
+
+	// nil options are empty options
+	res, err = mc.CallWithOptions(nil, balanceOfCalls(1))
+	require.NoError(t, err)
+	require.Equal(t, header.Hash(), res.BlockHash)
+	require.Equal(t, "latest", service.block)
+	require.Nil(t, service.stateOverride)
+	require.Nil(t, service.blockOverrides)
+
+	// overrides would silently be dropped by other backends
+	mc, err = NewMulticall3(&fakeBackend{output: service.output}, common.Address{})
+	require.NoError(t, err)
+	_, err = mc.CallWithOptions(&CallOptions{BlockTag: blockTag(rpc.FinalizedBlockNumber)}, balanceOfCalls(1))
+	require.ErrorIs(t, err, ErrCallOptionsUnsupported)
+	_, err = mc.CallWithOptions(&CallOptions{}, balanceOfCalls(1))
+	require.NoError(t, err)
+	_, err = mc.CallWithOptions(nil, balanceOfCalls(1))
+	require.NoError(t, err)
+}
+
+func TestRPCCaller_WithOptions(t *testing.T) {
+	service := &fakeEthService{output: packAggregate3(t, 9,
+		contract.Multicall3Result{Success: true, ReturnData: common.LeftPadBytes([]byte{5}, 32)},
+	)}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", service))
+	defer server.Stop()
+
+	owner := common.HexToAddress("0x01")
+	caller := NewRPCCaller(rpc.DialInProc(server)).WithOptions(&CallOptions{
+		BlockTag:      blockTag(rpc.FinalizedBlockNumber),
+		StateOverride: StateOverride{owner: {Code: []byte{1}}},
+	})
+	mc, err := NewMulticall3(caller, common.Address{})
+	require.NoError(t, err)
+
+	_, err = NewBatcher(mc).Call(nil, balanceOfCalls(1))
+	require.NoError(t, err)
+	require.Equal(t, "finalized", service.block)
+	require.JSONEq(t, `{"0x0000000000000000000000000000000000000001":{"code":"0x01"}}`, string(service.stateOverride))
+
+	// a pinned block number wins over the tag
+	_, err = mc.Call(&bind.CallOpts{BlockNumber: big.NewInt(9)}, balanceOfCalls(1))
+	require.NoError(t, err)
+	require.Equal(t, "0x9", service.block)
+
+	code, err := caller.CodeAt(context.Background(), owner, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1}, code)
+
+	mc, err = NewMulticall3(NewRPCCaller(rpc.DialInProc(server)).WithOptions(nil), common.Address{})
+	require.NoError(t, err)
+	_, err = mc.Call(nil, balanceOfCalls(1))
+	require.NoError(t, err)
+	require.Equal(t, "latest", service.block)
+	require.Nil(t, service.stateOverride)
+}
@@ -0,0 +1,110 @@
+// Package presets builds typed ViewCalls of common contracts: ERC-20, ERC-721, ERC-1155 tokens,
+// Uniswap V2 and V3 pools and the ether balance of Multicall3.
+//
+// Token amounts are returned as decimal.Decimal scaled by the decimals of the token.
+package presets
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hawkneo/utils/math/decimal"
+	"github.com/hawkneo/utils/multicall"
+	"math/big"
+)
+
+// ERC20Name reads the name of token, as a string or as the bytes32 of older tokens like MKR
+func ERC20Name(token common.Address, callback func(name string, err error)) multicall.ViewCall {
+	return &stringCall{multicall.NewTypedCall[string](token, "name()(string)", []interface{}{}, callback)}
+}
+
+// ERC20Symbol reads the symbol of token, as a string or as the bytes32 of older tokens like MKR
+func ERC20Symbol(token common.Address, callback func(symbol string, err error)) multicall.ViewCall {
+	return &stringCall{multicall.NewTypedCall[string](token, "symbol()(string)", []interface{}{}, callback)}
+}
+
+func ERC20Decimals(token common.Address, callback func(decimals uint8, err error)) multicall.ViewCall {
+	return multicall.NewTypedCall[uint8](token, "decimals()(uint8)", []interface{}{}, callback)
+}
+
+// ERC20TotalSupply reads the total supply of token with decimals
+func ERC20TotalSupply(token common.Address, decimals uint8, callback func(totalSupply decimal.Decimal, err error)) multicall.ViewCall {
+	return newAmountCall(token, "totalSupply()(uint256)", []interface{}{}, decimals, callback)
+}
+
+// ERC20BalanceOf reads the balance of owner in token with decimals
+func ERC20BalanceOf(token, owner common.Address, decimals uint8, callback func(balance decimal.Decimal, err error)) multicall.ViewCall {
+	return newAmountCall(token, "balanceOf(address)(uint256)", []interface{}{owner}, decimals, callback)
+}
+
+// ERC20Allowance reads the amount of token with decimals spender may transfer from owner
+func ERC20Allowance(token, owner, spender common.Address, decimals uint8, callback func(allowance decimal.Decimal, err error)) multicall.ViewCall {
+	return newAmountCall(token, "allowance(address,address)(uint256)", []interface{}{owner, spender}, decimals, callback)
+}
+
+// stringCall reads a string, or a bytes32 padded with zero bytes if the return data is not a string
+type stringCall struct {
+	multicall.ViewCall
+}
+
+func (call *stringCall) Decode(raw []byte) ([]interface{}, error) {
+	returnValues, err := call.ViewCall.Decode(raw)
+	if err == nil || len(raw) != 32 {
+		return returnValues, err
+	}
+	return []interface{}{string(bytes.TrimRight(raw, "\x00"))}, nil
+}
+
+// EstimatedReturnSize forwards the estimate of the embedded call, which the embedding interface hides
+func (call *stringCall) EstimatedReturnSize() int {
+	return multicall.EstimateReturnSize(call.ViewCall)
+}
+
+// amountCall reads a uint256 amount and scales it by decimals
+type amountCall struct {
+	multicall.ViewCall
+	decimals uint8
+	callback func(amount decimal.Decimal, err error)
+}
+
+func newAmountCall(target common.Address, method string, arguments []interface{}, decimals uint8, callback func(amount decimal.Decimal, err error)) multicall.ViewCall {
+	return &amountCall{
+		ViewCall: multicall.NewViewCall(target, method, arguments, nil),
+		decimals: decimals,
+		callback: callback,
+	}
+}
+
+// EstimatedReturnSize forwards the estimate of the embedded call, which the embedding interface hides
+func (call *amountCall) EstimatedReturnSize() int {
+	return multicall.EstimateReturnSize(call.ViewCall)
+}
+
+// Callback scales the decoded amount before calling the callback.
+// The returned error is the call, decode or scale error.
+func (call *amountCall) Callback() func(err error, returnValues []interface{}) error {
+	return func(err error, returnValues []interface{}) error {
+		amount := decimal.Zero
+		if err == nil {
+			amount, err = call.scale(returnValues)
+		}
+		if call.callback != nil {
+			call.callback(amount, err)
+		}
+		return err
+	}
+}
+
+func (call *amountCall) scale(returnValues []interface{}) (decimal.Decimal, error) {
+	if int(call.decimals) > decimal.MaxPrecision {
+		return decimal.Zero, fmt.Errorf("cannot scale amount of %s by %d decimals", call.Target(), call.decimals)
+	}
+	if len(returnValues) != 1 {
+		return decimal.Zero, fmt.Errorf("expected 1 return value of %s, got %d", call.Target(), len(returnValues))
+	}
+	value, ok := returnValues[0].(*big.Int)
+	if !ok {
+		return decimal.Zero, fmt.Errorf("cannot convert return value of %s into an amount: %T", call.Target(), returnValues[0])
+	}
+	return decimal.NewFromBigIntWithPrec(value, int(call.decimals)), nil
+}
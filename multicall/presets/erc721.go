@@ -0,0 +1,31 @@
+package presets
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hawkneo/utils/multicall"
+	"math/big"
+)
+
+// ERC721BalanceOf reads the number of tokens of owner in collection
+func ERC721BalanceOf(collection, owner common.Address, callback func(balance *big.Int, err error)) multicall.ViewCall {
+	return multicall.NewTypedCall[*big.Int](collection, "balanceOf(address)(uint256)", []interface{}{owner}, callback)
+}
+
+// ERC721OwnerOf reads the owner of tokenID, the call fails for tokens which do not exist
+func ERC721OwnerOf(collection common.Address, tokenID *big.Int, callback func(owner common.Address, err error)) multicall.ViewCall {
+	return multicall.NewTypedCallWithAllowFailure[common.Address](collection, "ownerOf(uint256)(address)", []interface{}{tokenID}, callback, true)
+}
+
+func ERC721TokenURI(collection common.Address, tokenID *big.Int, callback func(uri string, err error)) multicall.ViewCall {
+	return multicall.NewTypedCallWithAllowFailure[string](collection, "tokenURI(uint256)(string)", []interface{}{tokenID}, callback, true)
+}
+
+// ERC1155BalanceOf reads the balance of owner of token id in collection
+func ERC1155BalanceOf(collection, owner common.Address, id *big.Int, callback func(balance *big.Int, err error)) multicall.ViewCall {
+	return multicall.NewTypedCall[*big.Int](collection, "balanceOf(address,uint256)(uint256)", []interface{}{owner, id}, callback)
+}
+
+// ERC1155BalanceOfBatch reads the balances of owners[i] of token ids[i] in collection
+func ERC1155BalanceOfBatch(collection common.Address, owners []common.Address, ids []*big.Int, callback func(balances []*big.Int, err error)) multicall.ViewCall {
+	return multicall.NewTypedCall[[]*big.Int](collection, "balanceOfBatch(address[],uint256[])(uint256[])", []interface{}{owners, ids}, callback)
+}
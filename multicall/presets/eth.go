@@ -0,0 +1,15 @@
+package presets
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hawkneo/utils/math/decimal"
+	"github.com/hawkneo/utils/multicall"
+)
+
+// EtherDecimals is the number of decimals of ether amounts in wei
+const EtherDecimals = 18
+
+// EthBalance reads the ether balance of owner with getEthBalance of the Multicall3 contract at multicall3
+func EthBalance(multicall3, owner common.Address, callback func(balance decimal.Decimal, err error)) multicall.ViewCall {
+	return newAmountCall(multicall3, "getEthBalance(address)(uint256)", []interface{}{owner}, EtherDecimals, callback)
+}
@@ -0,0 +1,144 @@
+package presets
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hawkneo/utils/math/decimal"
+	"github.com/hawkneo/utils/multicall"
+	"github.com/stretchr/testify/require"
+)
+
+// callWithOutput decodes output as the return data of call and runs its callback
+func callWithOutput(t *testing.T, call multicall.ViewCall, types []string, values ...interface{}) error {
+	args := make(abi.Arguments, len(types))
+	for i, typ := range types {
+		abiType, err := abi.NewType(typ, "", nil)
+		require.NoError(t, err)
+		args[i] = abi.Argument{Type: abiType}
+	}
+	output, err := args.Pack(values...)
+	require.NoError(t, err)
+	returnValues, err := call.Decode(output)
+	require.NoError(t, err)
+	return call.Callback()(nil, returnValues)
+}
+
+func TestERC20(t *testing.T) {
+	token, owner := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+
+	var name, symbol string
+	require.NoError(t, callWithOutput(t, ERC20Name(token, func(value string, err error) {
+		name = value
+	}), []string{"string"}, "Test Token"))
+	require.Equal(t, "Test Token", name)
+	require.NoError(t, callWithOutput(t, ERC20Symbol(token, func(value string, err error) {
+		symbol = value
+	}), []string{"string"}, "TT"))
+	require.Equal(t, "TT", symbol)
+
+	// MKR returns its name and symbol as bytes32
+	var mkr [32]byte
+	copy(mkr[:], "MKR")
+	require.NoError(t, callWithOutput(t, ERC20Symbol(token, func(value string, err error) {
+		symbol = value
+	}), []string{"bytes32"}, mkr))
+	require.Equal(t, "MKR", symbol)
+	copy(mkr[:], "Maker")
+	require.NoError(t, callWithOutput(t, ERC20Name(token, func(value string, err error) {
+		name = value
+	}), []string{"bytes32"}, mkr))
+	require.Equal(t, "Maker", name)
+	_, err := ERC20Name(token, nil).Decode([]byte{1})
+	require.Error(t, err)
+
+	var decimals uint8
+	require.NoError(t, callWithOutput(t, ERC20Decimals(token, func(value uint8, err error) {
+		decimals = value
+	}), []string{"uint8"}, uint8(6)))
+	require.Equal(t, uint8(6), decimals)
+
+	var balance decimal.Decimal
+	require.NoError(t, callWithOutput(t, ERC20BalanceOf(token, owner, 6, func(value decimal.Decimal, err error) {
+		balance = value
+	}), []string{"uint256"}, big.NewInt(1_234_567)))
+	require.Equal(t, "1.234567", balance.String())
+
+	err = callWithOutput(t, ERC20BalanceOf(token, owner, 200, func(value decimal.Decimal, err error) {
+		require.Error(t, err)
+	}), []string{"uint256"}, big.NewInt(1))
+	require.Error(t, err)
+
+	// the batcher sizes the chunks with the estimate of the wrapped calls
+	for call, size := range map[multicall.ViewCall]int{
+		ERC20Name(token, nil):                32 + 32 + 256,
+		ERC20Decimals(token, nil):            32,
+		ERC20BalanceOf(token, owner, 6, nil): 32,
+	} {
+		estimator, ok := call.(multicall.ReturnSizeEstimator)
+		require.True(t, ok)
+		require.Equal(t, size, estimator.EstimatedReturnSize())
+	}
+
+	// a wrapped call without estimate is assumed to return dynamic data
+	hidden := struct{ multicall.ViewCall }{multicall.NewViewCall(token, "decimals()(uint8)", []interface{}{}, nil)}
+	require.Equal(t, 256, (&stringCall{ViewCall: hidden}).EstimatedReturnSize())
+	require.Equal(t, 256, (&amountCall{ViewCall: hidden}).EstimatedReturnSize())
+}
+
+func TestERC721(t *testing.T) {
+	collection, owner := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+
+	var balance *big.Int
+	require.NoError(t, callWithOutput(t, ERC721BalanceOf(collection, owner, func(value *big.Int, err error) {
+		balance = value
+	}), []string{"uint256"}, big.NewInt(3)))
+	require.Equal(t, big.NewInt(3), balance)
+
+	var tokenOwner common.Address
+	call := ERC721OwnerOf(collection, big.NewInt(7), func(value common.Address, err error) {
+		tokenOwner = value
+	})
+	require.True(t, call.AllowFailure())
+	require.NoError(t, callWithOutput(t, call, []string{"address"}, owner))
+	require.Equal(t, owner, tokenOwner)
+
+	var balances []*big.Int
+	require.NoError(t, callWithOutput(t, ERC1155BalanceOfBatch(collection, []common.Address{owner, owner}, []*big.Int{big.NewInt(1), big.NewInt(2)},
+		func(value []*big.Int, err error) {
+			balances = value
+		}), []string{"uint256[]"}, []*big.Int{big.NewInt(10), big.NewInt(20)}))
+	require.Equal(t, []*big.Int{big.NewInt(10), big.NewInt(20)}, balances)
+}
+
+func TestUniswap(t *testing.T) {
+	pool := common.HexToAddress("0x01")
+
+	var reserves UniswapV2Reserves
+	require.NoError(t, callWithOutput(t, UniswapV2GetReserves(pool, func(value UniswapV2Reserves, err error) {
+		reserves = value
+	}), []string{"uint112", "uint112", "uint32"}, big.NewInt(100), big.NewInt(200), uint32(1700000000)))
+	require.Equal(t, UniswapV2Reserves{Reserve0: big.NewInt(100), Reserve1: big.NewInt(200), BlockTimestampLast: 1700000000}, reserves)
+
+	var slot0 UniswapV3Slot0
+	require.NoError(t, callWithOutput(t, UniswapV3GetSlot0(pool, func(value UniswapV3Slot0, err error) {
+		slot0 = value
+	}), []string{"uint160", "int24", "uint16", "uint16", "uint16", "uint8", "bool"},
+		big.NewInt(1<<40), big.NewInt(-887272), uint16(1), uint16(2), uint16(3), uint8(0), true))
+	require.Equal(t, UniswapV3Slot0{
+		SqrtPriceX96:               big.NewInt(1 << 40),
+		Tick:                       big.NewInt(-887272),
+		ObservationIndex:           1,
+		ObservationCardinality:     2,
+		ObservationCardinalityNext: 3,
+		Unlocked:                   true,
+	}, slot0)
+
+	var fee *big.Int
+	require.NoError(t, callWithOutput(t, UniswapV3Fee(pool, func(value *big.Int, err error) {
+		fee = value
+	}), []string{"uint24"}, big.NewInt(3000)))
+	require.Equal(t, big.NewInt(3000), fee)
+}
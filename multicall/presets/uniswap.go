@@ -0,0 +1,56 @@
+package presets
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hawkneo/utils/multicall"
+	"math/big"
+)
+
+// UniswapV2Reserves is the result of getReserves of a Uniswap V2 pair
+type UniswapV2Reserves struct {
+	Reserve0           *big.Int
+	Reserve1           *big.Int
+	BlockTimestampLast uint32
+}
+
+// UniswapV3Slot0 is the result of slot0 of a Uniswap V3 pool
+type UniswapV3Slot0 struct {
+	SqrtPriceX96               *big.Int
+	Tick                       *big.Int
+	ObservationIndex           uint16
+	ObservationCardinality     uint16
+	ObservationCardinalityNext uint16
+	FeeProtocol                uint8
+	Unlocked                   bool
+}
+
+// PoolToken0 reads token0 of a Uniswap V2 pair or V3 pool
+func PoolToken0(pool common.Address, callback func(token common.Address, err error)) multicall.ViewCall {
+	return multicall.NewTypedCall[common.Address](pool, "token0()(address)", []interface{}{}, callback)
+}
+
+// PoolToken1 reads token1 of a Uniswap V2 pair or V3 pool
+func PoolToken1(pool common.Address, callback func(token common.Address, err error)) multicall.ViewCall {
+	return multicall.NewTypedCall[common.Address](pool, "token1()(address)", []interface{}{}, callback)
+}
+
+func UniswapV2GetReserves(pair common.Address, callback func(reserves UniswapV2Reserves, err error)) multicall.ViewCall {
+	return multicall.NewTypedCall[UniswapV2Reserves](pair,
+		"getReserves()(uint112 reserve0, uint112 reserve1, uint32 blockTimestampLast)", []interface{}{}, callback)
+}
+
+func UniswapV3GetSlot0(pool common.Address, callback func(slot0 UniswapV3Slot0, err error)) multicall.ViewCall {
+	return multicall.NewTypedCall[UniswapV3Slot0](pool,
+		"slot0()(uint160 sqrtPriceX96, int24 tick, uint16 observationIndex, uint16 observationCardinality, uint16 observationCardinalityNext, uint8 feeProtocol, bool unlocked)",
+		[]interface{}{}, callback)
+}
+
+// UniswapV3Liquidity reads the in-range liquidity of pool
+func UniswapV3Liquidity(pool common.Address, callback func(liquidity *big.Int, err error)) multicall.ViewCall {
+	return multicall.NewTypedCall[*big.Int](pool, "liquidity()(uint128)", []interface{}{}, callback)
+}
+
+// UniswapV3Fee reads the fee of pool in hundredths of a bip
+func UniswapV3Fee(pool common.Address, callback func(fee *big.Int, err error)) multicall.ViewCall {
+	return multicall.NewTypedCall[*big.Int](pool, "fee()(uint24)", []interface{}{}, callback)
+}
@@ -0,0 +1,142 @@
+// Package prometheus records the requests of a multicall.HookedCaller as Prometheus metrics.
+//
+// OpenTelemetry is out of scope of this package: a multicall.Hook recording OpenTelemetry metrics or spans is
+// implemented the same way, OnRequest returns the context of the request so a span can be started there.
+package prometheus
+
+import (
+	"context"
+
+	"github.com/hawkneo/utils/multicall"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	_ multicall.Hook       = (*Hook)(nil)
+	_ prometheus.Collector = (*Hook)(nil)
+)
+
+// NewHook returns a Hook which records the requests of a multicall.HookedCaller in Prometheus metrics
+// prefixed with namespace. The Hook is a prometheus.Collector, register it to expose the metrics:
+//
+//	hook := NewHook("app")
+//	prometheus.MustRegister(hook)
+//	caller := multicall.NewHookedCaller(contract, hook)
+//
+// The failed calls are counted without labels, WithTargetLabel labels them by target.
+func NewHook(namespace string, opts ...HookOption) *Hook {
+	h := &Hook{}
+	for _, opt := range opts {
+		opt(h)
+	}
+	var failureLabels []string
+	if h.targetLabel {
+		failureLabels = []string{"target"}
+	}
+	h.requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "multicall",
+		Name:      "requests_total",
+		Help:      "Number of multicall requests by result.",
+	}, []string{"result"})
+	h.duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "multicall",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of multicall requests by result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"result"})
+	h.batchSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "multicall",
+		Name:      "batch_size",
+		Help:      "Number of calls per multicall request.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	})
+	h.callDataBytes = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "multicall",
+		Name:      "calldata_bytes",
+		Help:      "Calldata bytes of the calls per multicall request.",
+		Buckets:   prometheus.ExponentialBuckets(64, 4, 10),
+	})
+	h.callFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "multicall",
+		Name:      "call_failures_total",
+		Help:      "Number of failed calls, by target with WithTargetLabel.",
+	}, failureLabels)
+	h.gasUsed = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "multicall",
+		Name:      "call_gas_used",
+		Help:      "Gas used per call, only reported by AggregateMulticall.",
+		Buckets:   prometheus.ExponentialBuckets(1000, 4, 10),
+	})
+	return h
+}
+
+// HookOption configures a Hook
+type HookOption func(*Hook)
+
+// WithTargetLabel labels the failed calls by target address. Every target is a new time series, only use it with
+// a bounded set of targets.
+func WithTargetLabel() HookOption {
+	return func(h *Hook) {
+		h.targetLabel = true
+	}
+}
+
+type Hook struct {
+	targetLabel   bool
+	requests      *prometheus.CounterVec
+	duration      *prometheus.HistogramVec
+	batchSize     prometheus.Histogram
+	callDataBytes prometheus.Histogram
+	callFailures  *prometheus.CounterVec
+	gasUsed       prometheus.Histogram
+}
+
+func (h *Hook) OnRequest(ctx context.Context, request *multicall.RequestInfo) context.Context {
+	h.batchSize.Observe(float64(len(request.Calls)))
+	h.callDataBytes.Observe(float64(request.CallDataSize))
+	return ctx
+}
+
+func (h *Hook) OnResponse(ctx context.Context, request *multicall.RequestInfo, response *multicall.ResponseInfo) {
+	result := "success"
+	if response.Err != nil {
+		result = "error"
+	}
+	h.requests.WithLabelValues(result).Inc()
+	h.duration.WithLabelValues(result).Observe(response.Duration.Seconds())
+}
+
+func (h *Hook) OnCallDecoded(ctx context.Context, request *multicall.RequestInfo, result *multicall.CallResult) {
+	if result.Error != nil {
+		if h.targetLabel {
+			h.callFailures.WithLabelValues(result.Call.Target().Hex()).Inc()
+		} else {
+			h.callFailures.WithLabelValues().Inc()
+		}
+	}
+	if result.GasUsed != nil && result.GasUsed.IsUint64() {
+		h.gasUsed.Observe(float64(result.GasUsed.Uint64()))
+	}
+}
+
+func (h *Hook) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range h.collectors() {
+		collector.Describe(ch)
+	}
+}
+
+func (h *Hook) Collect(ch chan<- prometheus.Metric) {
+	for _, collector := range h.collectors() {
+		collector.Collect(ch)
+	}
+}
+
+func (h *Hook) collectors() []prometheus.Collector {
+	return []prometheus.Collector{h.requests, h.duration, h.batchSize, h.callDataBytes, h.callFailures, h.gasUsed}
+}
@@ -0,0 +1,64 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hawkneo/utils/multicall"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHook(t *testing.T) {
+	hook := NewHook("test")
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(hook))
+
+	target := common.HexToAddress("0x01")
+	call := multicall.NewViewCall(target, "balanceOf(address)(uint256)", []interface{}{common.Address{}}, nil)
+	request := &multicall.RequestInfo{Calls: multicall.ViewCalls{call, call}, CallDataSize: 72}
+	ctx := hook.OnRequest(context.Background(), request)
+	hook.OnResponse(ctx, request, &multicall.ResponseInfo{Duration: time.Second})
+	hook.OnCallDecoded(ctx, request, &multicall.CallResult{Call: call, GasUsed: big.NewInt(30000)})
+	hook.OnCallDecoded(ctx, request, &multicall.CallResult{Call: call, Error: errors.New("reverted")})
+	hook.OnResponse(ctx, request, &multicall.ResponseInfo{Err: errors.New("timeout")})
+
+	require.Equal(t, float64(1), testutil.ToFloat64(hook.requests.WithLabelValues("success")))
+	require.Equal(t, float64(1), testutil.ToFloat64(hook.requests.WithLabelValues("error")))
+	require.Equal(t, float64(1), testutil.ToFloat64(hook.callFailures.WithLabelValues()))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	names := make([]string, 0)
+	for _, family := range families {
+		names = append(names, family.GetName())
+		if family.GetName() == "test_multicall_batch_size" {
+			require.Equal(t, float64(2), family.GetMetric()[0].GetHistogram().GetSampleSum())
+		}
+		if family.GetName() == "test_multicall_call_gas_used" {
+			require.Equal(t, uint64(1), family.GetMetric()[0].GetHistogram().GetSampleCount())
+		}
+	}
+	require.ElementsMatch(t, []string{
+		"test_multicall_requests_total",
+		"test_multicall_request_duration_seconds",
+		"test_multicall_batch_size",
+		"test_multicall_calldata_bytes",
+		"test_multicall_call_failures_total",
+		"test_multicall_call_gas_used",
+	}, names)
+}
+
+func TestHook_WithTargetLabel(t *testing.T) {
+	hook := NewHook("test", WithTargetLabel())
+	target := common.HexToAddress("0x01")
+	call := multicall.NewViewCall(target, "balanceOf(address)(uint256)", []interface{}{common.Address{}}, nil)
+	request := &multicall.RequestInfo{Calls: multicall.ViewCalls{call}}
+	hook.OnCallDecoded(context.Background(), request, &multicall.CallResult{Call: call, Error: errors.New("reverted")})
+	require.Equal(t, float64(1), testutil.ToFloat64(hook.callFailures.WithLabelValues(target.Hex())))
+}
@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/hawkneo/utils/multicall"
+	"github.com/redis/go-redis/v9"
+)
+
+const DefaultKeyPrefix = "multicall:"
+
+var (
+	_ multicall.CacheBackend = (*RedisCache)(nil)
+)
+
+// NewRedisCache returns a CacheBackend based on go-redis package.
+// Keys are prefixed with DefaultKeyPrefix and expire after ttl, zero means no expiration.
+//
+// The keys of a multicall.CachedCaller request share a hash tag, so their MGET works with a Redis Cluster
+// client as long as the prefix does not contain a hash tag.
+func NewRedisCache(client redis.Cmdable, ttl time.Duration) *RedisCache {
+	return &RedisCache{
+		cli:    client,
+		prefix: DefaultKeyPrefix,
+		ttl:    ttl,
+	}
+}
+
+type RedisCache struct {
+	cli    redis.Cmdable
+	prefix string
+	ttl    time.Duration
+}
+
+// WithPrefix returns a copy of the cache using prefix for its keys
+func (r RedisCache) WithPrefix(prefix string) *RedisCache {
+	r.prefix = prefix
+	return &r
+}
+
+func (r RedisCache) Get(ctx context.Context, keys []string) (map[string][]byte, error) {
+	values := make(map[string][]byte)
+	if len(keys) == 0 {
+		return values, nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = r.prefix + key
+	}
+	res, err := r.cli.MGet(ctx, prefixed...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range res {
+		if s, ok := value.(string); ok {
+			values[keys[i]] = []byte(s)
+		}
+	}
+	return values, nil
+}
+
+func (r RedisCache) Set(ctx context.Context, values map[string][]byte) error {
+	_, err := r.cli.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range values {
+			pipe.Set(ctx, r.prefix+key, value, r.ttl)
+		}
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,85 @@
+package redis
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hawkneo/utils/multicall"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisCache(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	ctx := context.Background()
+
+	cache := NewRedisCache(client, time.Minute)
+	values, err := cache.Get(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	require.NoError(t, cache.Set(ctx, map[string][]byte{"a": {1}, "b": {}}))
+	values, err = cache.Get(ctx, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"a": {1}, "b": {}}, values)
+	require.True(t, server.Exists(DefaultKeyPrefix+"a"))
+	require.Equal(t, time.Minute, server.TTL(DefaultKeyPrefix+"a"))
+
+	server.FastForward(time.Minute)
+	values, err = cache.Get(ctx, []string{"a"})
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	other := cache.WithPrefix("other:")
+	require.NoError(t, other.Set(ctx, map[string][]byte{"a": {2}}))
+	require.True(t, server.Exists("other:a"))
+	values, err = cache.Get(ctx, []string{"a"})
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	server.SetError("connection refused")
+	_, err = cache.Get(ctx, []string{"a"})
+	require.Error(t, err)
+	require.Error(t, cache.Set(ctx, map[string][]byte{"a": {1}}))
+}
+
+// multiCaller returns the calldata of each call as its return data
+type multiCaller struct{}
+
+func (multiCaller) Call(opts *bind.CallOpts, calls multicall.ViewCalls) (*multicall.Result, error) {
+	result := &multicall.Result{BlockNumber: opts.BlockNumber.Uint64()}
+	for _, call := range calls {
+		data, err := call.CallData()
+		if err != nil {
+			return nil, err
+		}
+		result.Calls = append(result.Calls, multicall.CallResult{Call: call, Raw: data, Success: true})
+	}
+	return result, nil
+}
+
+func TestRedisCache_CachedCaller(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	caller := multicall.NewCachedCaller(multiCaller{}, big.NewInt(1), NewRedisCache(client, 0))
+
+	calls := multicall.ViewCalls{
+		multicall.NewViewCall(common.HexToAddress("0x1"), "balanceOf(address)(uint256)", []interface{}{common.HexToAddress("0x2")}, nil),
+		multicall.NewViewCall(common.HexToAddress("0x1"), "balanceOf(address)(uint256)", []interface{}{common.HexToAddress("0x3")}, nil),
+	}
+	_, err := caller.Call(&bind.CallOpts{BlockNumber: big.NewInt(100)}, calls)
+	require.NoError(t, err)
+
+	// the keys of a request share the hash tag of the chain and block, for Redis Cluster
+	keys := server.Keys()
+	require.Len(t, keys, 2)
+	for _, key := range keys {
+		require.Contains(t, key, DefaultKeyPrefix+"{1:100}:")
+	}
+}
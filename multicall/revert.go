@@ -0,0 +1,127 @@
+package multicall
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+	"strings"
+)
+
+var (
+	// Error(string)
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+	// Panic(uint256)
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+	uint256Type, _ = abi.NewType("uint256", "", nil)
+	panicError     = abi.NewError("Panic", abi.Arguments{{Name: "code", Type: uint256Type}})
+)
+
+// panicReasons describes the Solidity panic codes, see
+// https://docs.soliditylang.org/en/latest/control-structures.html#panic-via-assert-and-error-via-require
+var panicReasons = map[uint64]string{
+	0x00: "generic panic",
+	0x01: "assert(false)",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "enum overflow",
+	0x22: "invalid encoded storage byte array accessed",
+	0x31: "out-of-bounds array access; popping on an empty array",
+	0x32: "out-of-bounds access of an array or bytesN",
+	0x41: "out of memory",
+	0x51: "uninitialized function",
+}
+
+// RevertError is the error of a failed call, decoded from its return data.
+//
+// Exactly one of Reason, PanicCode and Name is set if the return data is a
+// Error(string), a Panic(uint256) or a custom error of the ABI passed to DecodeRevert respectively.
+type RevertError struct {
+	Data      []byte        // raw return data
+	Reason    string        // reason of Error(string)
+	PanicCode *big.Int      // code of Panic(uint256)
+	Name      string        // name of the custom error
+	Args      []interface{} // arguments of the custom error
+	GasLimit  *big.Int      // only set by Multicall
+	GasUsed   *big.Int      // only set by Multicall
+}
+
+// DecodeRevert decodes the return data of a failed call, custom errors are decoded with the errors
+// of parsed, which may be nil
+func DecodeRevert(data []byte, parsed *abi.ABI) *RevertError {
+	revertErr := &RevertError{Data: data}
+	if len(data) < 4 {
+		return revertErr
+	}
+
+	switch {
+	case bytes.Equal(data[:4], errorSelector):
+		if reason, err := abi.UnpackRevert(data); err == nil {
+			revertErr.Reason = reason
+		}
+	case bytes.Equal(data[:4], panicSelector):
+		if args, err := panicError.Inputs.Unpack(data[4:]); err == nil {
+			revertErr.PanicCode = args[0].(*big.Int)
+		}
+	case parsed != nil:
+		for _, customErr := range parsed.Errors {
+			if !bytes.Equal(data[:4], customErr.ID[:4]) {
+				continue
+			}
+			if args, err := customErr.Inputs.Unpack(data[4:]); err == nil {
+				revertErr.Name = customErr.Name
+				revertErr.Args = args
+			}
+			break
+		}
+	}
+	return revertErr
+}
+
+// mergeErrors returns an ABI with the custom errors of base and parsed, keyed by signature so errors of the same
+// name in different ABIs are kept
+func mergeErrors(base *abi.ABI, parsed ...abi.ABI) *abi.ABI {
+	merged := &abi.ABI{Errors: make(map[string]abi.Error)}
+	if base != nil {
+		for _, customErr := range base.Errors {
+			merged.Errors[customErr.Sig] = customErr
+		}
+	}
+	for _, p := range parsed {
+		for _, customErr := range p.Errors {
+			merged.Errors[customErr.Sig] = customErr
+		}
+	}
+	return merged
+}
+
+func (e *RevertError) Error() string {
+	var msg string
+	switch {
+	case e.Reason != "":
+		msg = fmt.Sprintf("execution reverted: %s", e.Reason)
+	case e.PanicCode != nil:
+		msg = fmt.Sprintf("execution reverted: panic code %#x", e.PanicCode)
+		if e.PanicCode.IsUint64() {
+			if reason, ok := panicReasons[e.PanicCode.Uint64()]; ok {
+				msg = fmt.Sprintf("%s (%s)", msg, reason)
+			}
+		}
+	case e.Name != "":
+		args := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = fmt.Sprintf("%v", arg)
+		}
+		msg = fmt.Sprintf("execution reverted: %s(%s)", e.Name, strings.Join(args, ", "))
+	case len(e.Data) > 0:
+		msg = fmt.Sprintf("execution reverted: %s", hexutil.Encode(e.Data))
+	default:
+		msg = "execution reverted"
+	}
+	if e.GasLimit != nil && e.GasUsed != nil {
+		msg = fmt.Sprintf("%s, gaslimit: %s, gasused: %s", msg, e.GasLimit, e.GasUsed)
+	}
+	return msg
+}
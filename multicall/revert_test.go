@@ -0,0 +1,203 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/hawkneo/utils/multicall/contract"
+	"github.com/stretchr/testify/require"
+)
+
+const insufficientBalanceABI = `[{"inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}],"name":"InsufficientBalance","type":"error"}]`
+
+func TestDecodeRevert(t *testing.T) {
+	stringType, _ := abi.NewType("string", "", nil)
+	reason, err := abi.Arguments{{Type: stringType}}.Pack("not owner")
+	require.NoError(t, err)
+	revertErr := DecodeRevert(append(append([]byte{}, errorSelector...), reason...), nil)
+	require.Equal(t, "not owner", revertErr.Reason)
+	require.Equal(t, "execution reverted: not owner", revertErr.Error())
+
+	panicData := append(append([]byte{}, panicSelector...), common.LeftPadBytes([]byte{0x11}, 32)...)
+	revertErr = DecodeRevert(panicData, nil)
+	require.Equal(t, big.NewInt(0x11), revertErr.PanicCode)
+	require.Contains(t, revertErr.Error(), "arithmetic underflow or overflow")
+	require.Equal(t, panicSelector, panicError.ID[:4])
+
+	// trailing data after the code is ignored by the abi decoder
+	revertErr = DecodeRevert(append(panicData, make([]byte, 32)...), nil)
+	require.Equal(t, big.NewInt(0x11), revertErr.PanicCode)
+	revertErr = DecodeRevert(panicData[:20], nil)
+	require.Nil(t, revertErr.PanicCode)
+
+	parsed, err := abi.JSON(strings.NewReader(insufficientBalanceABI))
+	require.NoError(t, err)
+	customErr := parsed.Errors["InsufficientBalance"]
+	args, err := customErr.Inputs.Pack(big.NewInt(1), big.NewInt(2))
+	require.NoError(t, err)
+	data := append(append([]byte{}, customErr.ID[:4]...), args...)
+
+	revertErr = DecodeRevert(data, nil)
+	require.Empty(t, revertErr.Name)
+	require.Equal(t, "execution reverted: "+hexutil.Encode(data), revertErr.Error())
+
+	revertErr = DecodeRevert(data, &parsed)
+	require.Equal(t, "InsufficientBalance", revertErr.Name)
+	require.Equal(t, []interface{}{big.NewInt(1), big.NewInt(2)}, revertErr.Args)
+	require.Equal(t, "execution reverted: InsufficientBalance(1, 2)", revertErr.Error())
+
+	require.Equal(t, "execution reverted", DecodeRevert(nil, nil).Error())
+}
+
+func TestMulticall3_RevertError(t *testing.T) {
+	stringType, _ := abi.NewType("string", "", nil)
+	reason, err := abi.Arguments{{Type: stringType}}.Pack("paused")
+	require.NoError(t, err)
+	backend := &fakeBackend{output: packAggregate3(t, 1,
+		contract.Multicall3Result{Success: false, ReturnData: append(append([]byte{}, errorSelector...), reason...)},
+	)}
+	mc, err := NewMulticall3(backend, common.Address{})
+	require.NoError(t, err)
+
+	res, err := mc.Call(nil, ViewCalls{
+		NewViewCallWithAllowFailure(common.Address{}, "balanceOf(address)(uint256)", []interface{}{common.Address{}}, nil, true),
+	})
+	require.NoError(t, err)
+	var revertErr *RevertError
+	require.True(t, errors.As(res.Calls[0].Error, &revertErr))
+	require.Equal(t, "paused", revertErr.Reason)
+
+	// a failed call which does not allow failure reverts aggregate3 on-chain, the reason is read by a
+	// second request
+	reverting := &revertingBackend{fakeBackend: backend, reverts: 1}
+	mc, err = NewMulticall3(reverting, common.Address{})
+	require.NoError(t, err)
+	_, err = mc.Call(nil, balanceOfCalls(1))
+	require.True(t, errors.As(err, &revertErr))
+	require.Equal(t, "paused", revertErr.Reason)
+	require.ErrorContains(t, err, "multicall3: call 0")
+
+	reverting.reverts = 2
+	_, err = mc.Call(nil, balanceOfCalls(1))
+	require.EqualError(t, err, "execution reverted: Multicall3: call failed")
+
+	// a revert without data, e.g. of a Multicall3 built without revert strings
+	reverting.reverts, reverting.err = 1, &rpcError{message: "execution reverted", code: -32000}
+	_, err = mc.Call(nil, balanceOfCalls(1))
+	require.True(t, errors.As(err, &revertErr))
+	require.Equal(t, "paused", revertErr.Reason)
+}
+
+// rpcError is a JSON-RPC error with data, a revert if code is 3
+type rpcError struct {
+	message string
+	code    int
+	data    interface{}
+}
+
+func (e *rpcError) Error() string          { return e.message }
+func (e *rpcError) ErrorCode() int         { return e.code }
+func (e *rpcError) ErrorData() interface{} { return e.data }
+
+func TestIsRevert(t *testing.T) {
+	require.True(t, isRevert(&RevertError{Reason: "paused"}))
+	require.True(t, isRevert(fmt.Errorf("call: %w", &rpcError{message: "execution reverted", code: 3, data: "0x"})))
+
+	// not a revert, e.g. a rate limit with data or an error message mentioning a revert
+	require.False(t, isRevert(&rpcError{message: "rate limited", code: -32005, data: map[string]interface{}{"retryAfter": 1}}))
+	require.False(t, isRevert(&rpcError{message: "execution reverted", code: 3}))
+	require.False(t, isRevert(&rpcError{message: "insufficient funds for gas * price + value", code: -32000}))
+	require.False(t, isRevert(errors.New("execution reverted")))
+
+	// revert() or require(condition) without data, over JSON-RPC and from the simulated backend
+	require.True(t, isRevert(fmt.Errorf("call: %w", &rpcError{message: "execution reverted", code: -32000})))
+	require.True(t, isRevert(fmt.Errorf("call: %w", vm.ErrExecutionReverted)))
+}
+
+// revertingBackend reverts the first eth_calls, with err or the revert of aggregate3
+type revertingBackend struct {
+	*fakeBackend
+	reverts int
+	err     error
+}
+
+func (b *revertingBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if b.reverts > 0 {
+		b.reverts--
+		if b.err != nil {
+			return nil, b.err
+		}
+		return nil, &rpcError{message: "execution reverted: Multicall3: call failed", code: 3, data: "0x"}
+	}
+	return b.fakeBackend.CallContract(ctx, call, blockNumber)
+}
+
+func TestMulticall3_WithErrors(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(insufficientBalanceABI))
+	require.NoError(t, err)
+	customErr := parsed.Errors["InsufficientBalance"]
+	args, err := customErr.Inputs.Pack(big.NewInt(1), big.NewInt(2))
+	require.NoError(t, err)
+	// the ABI of another target, with an error of the same name
+	other, err := abi.JSON(strings.NewReader(`[
+{"inputs":[{"name":"account","type":"address"}],"name":"Unauthorized","type":"error"},
+{"inputs":[{"name":"needed","type":"uint256"}],"name":"InsufficientBalance","type":"error"}
+]`))
+	require.NoError(t, err)
+	unauthorized := other.Errors["Unauthorized"]
+	unauthorizedArgs, err := unauthorized.Inputs.Pack(common.HexToAddress("0x01"))
+	require.NoError(t, err)
+	otherInsufficient := other.Errors["InsufficientBalance"]
+	otherInsufficientArgs, err := otherInsufficient.Inputs.Pack(big.NewInt(3))
+	require.NoError(t, err)
+
+	backend := &fakeBackend{output: packAggregate3(t, 1,
+		contract.Multicall3Result{Success: false, ReturnData: append(append([]byte{}, customErr.ID[:4]...), args...)},
+		contract.Multicall3Result{Success: false, ReturnData: append(append([]byte{}, unauthorized.ID[:4]...), unauthorizedArgs...)},
+		contract.Multicall3Result{Success: false, ReturnData: append(append([]byte{}, otherInsufficient.ID[:4]...), otherInsufficientArgs...)},
+	)}
+	mc, err := NewMulticall3(backend, common.Address{})
+	require.NoError(t, err)
+	call := NewViewCallWithAllowFailure(common.Address{}, "balanceOf(address)(uint256)", []interface{}{common.Address{}}, nil, true)
+	calls := ViewCalls{call, call, call}
+
+	// the errors are only known by the copy
+	withErrors := mc.WithErrors(parsed)
+	res, err := mc.Call(nil, calls)
+	require.NoError(t, err)
+	var revertErr *RevertError
+	require.True(t, errors.As(res.Calls[0].Error, &revertErr))
+	require.Empty(t, revertErr.Name)
+
+	res, err = withErrors.Call(nil, calls)
+	require.NoError(t, err)
+	require.True(t, errors.As(res.Calls[0].Error, &revertErr))
+	require.Equal(t, "InsufficientBalance", revertErr.Name)
+	require.Equal(t, []interface{}{big.NewInt(1), big.NewInt(2)}, revertErr.Args)
+	require.True(t, errors.As(res.Calls[1].Error, &revertErr))
+	require.Empty(t, revertErr.Name)
+
+	// the errors of several ABIs, added at once or one after another
+	for _, caller := range []*Multicall3{mc.WithErrors(parsed, other), withErrors.WithErrors(other)} {
+		res, err = caller.Call(nil, calls)
+		require.NoError(t, err)
+		require.True(t, errors.As(res.Calls[0].Error, &revertErr))
+		require.Equal(t, "InsufficientBalance", revertErr.Name)
+		require.Equal(t, []interface{}{big.NewInt(1), big.NewInt(2)}, revertErr.Args)
+		require.True(t, errors.As(res.Calls[1].Error, &revertErr))
+		require.Equal(t, "Unauthorized", revertErr.Name)
+		require.Equal(t, []interface{}{common.HexToAddress("0x01")}, revertErr.Args)
+		require.True(t, errors.As(res.Calls[2].Error, &revertErr))
+		require.Equal(t, "InsufficientBalance", revertErr.Name)
+		require.Equal(t, []interface{}{big.NewInt(3)}, revertErr.Args)
+	}
+}
@@ -0,0 +1,362 @@
+package multicall
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"strconv"
+)
+
+// Signature is a parsed human-readable method signature. Both the short form and
+// the Solidity form are supported, e.g.
+//
+//	balanceOf(address)(uint256)
+//	getPool((address token0, address token1) key)((uint160,int24)[2] slots)
+//	function getReserves() external view returns (uint112 reserve0, uint112 reserve1, uint32 blockTimestampLast)
+//
+// Tuples are written as `(...)` or `tuple(...)`, unnamed tuple components are named
+// arg0, arg1, ... in the order they appear.
+type Signature struct {
+	Name            string
+	StateMutability string // view if not specified
+	Inputs          abi.Arguments
+	Outputs         abi.Arguments
+}
+
+// SignatureError is returned for a malformed signature, Pos is the byte offset of the
+// offending token.
+type SignatureError struct {
+	Signature string
+	Pos       int
+	Msg       string
+}
+
+func (e *SignatureError) Error() string {
+	return fmt.Sprintf("invalid signature %q at position %d: %s", e.Signature, e.Pos, e.Msg)
+}
+
+// ParseSignature parses a human-readable method signature
+func ParseSignature(signature string) (*Signature, error) {
+	return parseSignature(signature, true)
+}
+
+// Method returns the abi.Method of the signature
+func (s *Signature) Method() abi.Method {
+	return abi.NewMethod(s.Name, s.Name, abi.Function, s.StateMutability, false, s.StateMutability == "payable", s.Inputs, s.Outputs)
+}
+
+// parseSignature parses signature, the output types are only resolved if resolveOutputs is set,
+// so calls with a custom decoder may use any type name in their outputs.
+func parseSignature(signature string, resolveOutputs bool) (*Signature, error) {
+	p := &signatureParser{signature: signature, resolveOutputs: resolveOutputs}
+	if err := p.lex(); err != nil {
+		return nil, err
+	}
+	name, inputs, outputs, mutability, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	sig := &Signature{Name: name, StateMutability: mutability}
+	if sig.Inputs, err = p.resolve(inputs); err != nil {
+		return nil, err
+	}
+	if resolveOutputs {
+		if sig.Outputs, err = p.resolve(outputs); err != nil {
+			return nil, err
+		}
+	}
+	return sig, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of signature"
+	}
+	return strconv.Quote(t.text)
+}
+
+// param is a parsed parameter, its type is resolved after parsing
+type param struct {
+	abi.ArgumentMarshaling
+	pos int
+}
+
+var (
+	mutabilityKeywords = map[string]bool{"view": true, "pure": true, "payable": true, "nonpayable": true, "constant": true}
+	visibilityKeywords = map[string]bool{"external": true, "public": true}
+	locationKeywords   = map[string]bool{"memory": true, "calldata": true, "storage": true, "indexed": true}
+)
+
+type signatureParser struct {
+	signature      string
+	resolveOutputs bool
+	lenient        bool // accept unknown elementary types
+	tokens         []token
+	index          int
+}
+
+func (p *signatureParser) errorf(pos int, format string, args ...interface{}) error {
+	return &SignatureError{Signature: p.signature, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *signatureParser) lex() error {
+	s := p.signature
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']' || c == ',':
+			p.tokens = append(p.tokens, token{kind: tokenPunct, text: s[i : i+1], pos: i})
+			i++
+		case isDigit(c):
+			start := i
+			for i < len(s) && isDigit(s[i]) {
+				i++
+			}
+			p.tokens = append(p.tokens, token{kind: tokenNumber, text: s[start:i], pos: start})
+		case isIdentStart(c):
+			start := i
+			for i < len(s) && (isIdentStart(s[i]) || isDigit(s[i])) {
+				i++
+			}
+			p.tokens = append(p.tokens, token{kind: tokenIdent, text: s[start:i], pos: start})
+		default:
+			return p.errorf(i, "unexpected character %q", c)
+		}
+	}
+	p.tokens = append(p.tokens, token{kind: tokenEOF, pos: len(s)})
+	return nil
+}
+
+func (p *signatureParser) peek() token {
+	return p.tokens[p.index]
+}
+
+func (p *signatureParser) next() token {
+	tok := p.tokens[p.index]
+	if tok.kind != tokenEOF {
+		p.index++
+	}
+	return tok
+}
+
+func (p *signatureParser) is(kind tokenKind, text string) bool {
+	tok := p.peek()
+	return tok.kind == kind && (text == "" || tok.text == text)
+}
+
+func (p *signatureParser) expect(kind tokenKind, text, what string) (token, error) {
+	if !p.is(kind, text) {
+		tok := p.peek()
+		return tok, p.errorf(tok.pos, "expected %s, found %s", what, tok)
+	}
+	return p.next(), nil
+}
+
+// parse parses
+//
+//	["function"] name "(" params ")" modifiers ["returns"] ["(" params ")"] modifiers
+func (p *signatureParser) parse() (name string, inputs, outputs []param, mutability string, err error) {
+	if p.is(tokenIdent, "function") && p.tokens[p.index+1].kind == tokenIdent {
+		p.next()
+	}
+	nameToken, err := p.expect(tokenIdent, "", "method name")
+	if err != nil {
+		return
+	}
+	name = nameToken.text
+	if _, err = p.expect(tokenPunct, "(", `"("`); err != nil {
+		return
+	}
+	if inputs, err = p.params(); err != nil {
+		return
+	}
+	if mutability, err = p.modifiers(""); err != nil {
+		return
+	}
+	p.lenient = !p.resolveOutputs
+	if p.is(tokenIdent, "returns") {
+		p.next()
+		if _, err = p.expect(tokenPunct, "(", `"(" after returns`); err != nil {
+			return
+		}
+		if outputs, err = p.params(); err != nil {
+			return
+		}
+	} else if p.is(tokenPunct, "(") {
+		p.next()
+		if outputs, err = p.params(); err != nil {
+			return
+		}
+	}
+	if mutability, err = p.modifiers(mutability); err != nil {
+		return
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		err = p.errorf(tok.pos, "unexpected %s", tok)
+		return
+	}
+	if mutability == "" || mutability == "constant" {
+		mutability = "view"
+	}
+	return
+}
+
+func (p *signatureParser) modifiers(mutability string) (string, error) {
+	for p.is(tokenIdent, "") {
+		tok := p.peek()
+		switch {
+		case mutabilityKeywords[tok.text]:
+			if mutability != "" {
+				return "", p.errorf(tok.pos, "duplicate state mutability %s", tok)
+			}
+			mutability = tok.text
+		case visibilityKeywords[tok.text]:
+		default:
+			return mutability, nil
+		}
+		p.next()
+	}
+	return mutability, nil
+}
+
+// params parses a comma separated parameter list, the opening parenthesis is already consumed
+func (p *signatureParser) params() ([]param, error) {
+	params := make([]param, 0)
+	if p.is(tokenPunct, ")") {
+		p.next()
+		return params, nil
+	}
+	for {
+		prm, err := p.param()
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, prm)
+		if p.is(tokenPunct, ",") {
+			p.next()
+			continue
+		}
+		if _, err := p.expect(tokenPunct, ")", `"," or ")"`); err != nil {
+			return nil, err
+		}
+		return params, nil
+	}
+}
+
+// param parses
+//
+//	type ["memory" | "calldata" | "storage" | "indexed"] [name]
+func (p *signatureParser) param() (param, error) {
+	prm, err := p.typ()
+	if err != nil {
+		return prm, err
+	}
+	if tok := p.peek(); tok.kind == tokenIdent && locationKeywords[tok.text] {
+		p.next()
+	}
+	if p.is(tokenIdent, "") {
+		prm.Name = p.next().text
+	}
+	return prm, nil
+}
+
+// typ parses
+//
+//	(elementary | ["tuple"] "(" params ")") ("[" [size] "]")*
+func (p *signatureParser) typ() (param, error) {
+	tok := p.peek()
+	prm := param{pos: tok.pos}
+	switch {
+	case p.is(tokenPunct, "(") || (p.is(tokenIdent, "tuple") && p.tokens[p.index+1].text == "("):
+		if tok.kind == tokenIdent {
+			p.next()
+		}
+		p.next()
+		components, err := p.params()
+		if err != nil {
+			return prm, err
+		}
+		prm.Type = "tuple"
+		for i, component := range components {
+			if component.Name == "" {
+				component.Name = fmt.Sprintf("arg%d", i)
+			}
+			prm.Components = append(prm.Components, component.ArgumentMarshaling)
+		}
+	case tok.kind == tokenIdent:
+		p.next()
+		prm.Type = normalizeType(tok.text)
+		if _, err := abi.NewType(prm.Type, "", nil); err != nil && !p.lenient {
+			return prm, p.errorf(tok.pos, "unknown type %s", tok)
+		}
+	default:
+		return prm, p.errorf(tok.pos, "expected type, found %s", tok)
+	}
+
+	for p.is(tokenPunct, "[") {
+		p.next()
+		if p.is(tokenNumber, "") {
+			size := p.next()
+			if n, err := strconv.Atoi(size.text); err != nil || n == 0 {
+				return prm, p.errorf(size.pos, "invalid array size %s", size)
+			}
+			prm.Type += "[" + size.text + "]"
+		} else {
+			prm.Type += "[]"
+		}
+		if _, err := p.expect(tokenPunct, "]", `"]"`); err != nil {
+			return prm, err
+		}
+	}
+	return prm, nil
+}
+
+// resolve creates the abi types of params
+func (p *signatureParser) resolve(params []param) (abi.Arguments, error) {
+	args := make(abi.Arguments, 0, len(params))
+	for _, prm := range params {
+		typ, err := abi.NewType(prm.Type, "", prm.Components)
+		if err != nil {
+			return nil, p.errorf(prm.pos, "invalid type %q: %v", prm.Type, err)
+		}
+		args = append(args, abi.Argument{Name: prm.Name, Type: typ})
+	}
+	return args, nil
+}
+
+// normalizeType replaces the aliases of elementary types with their canonical names
+func normalizeType(typ string) string {
+	switch typ {
+	case "uint":
+		return "uint256"
+	case "int":
+		return "int256"
+	case "byte":
+		return "bytes1"
+	}
+	return typ
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,118 @@
+package multicall
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSignature(t *testing.T) {
+	tests := []struct {
+		signature  string
+		sig        string
+		outputs    string
+		names      []string
+		mutability string
+	}{
+		{"balanceOf(address)(uint256)", "balanceOf(address)", "(uint256)", []string{""}, "view"},
+		{"name()", "name()", "()", []string{}, "view"},
+		{"transfer(address to, uint amount)", "transfer(address,uint256)", "()", []string{}, "view"},
+		{
+			"function getReserves() external view returns (uint112 reserve0, uint112 reserve1, uint32 blockTimestampLast)",
+			"getReserves()", "(uint112,uint112,uint32)", []string{"reserve0", "reserve1", "blockTimestampLast"}, "view",
+		},
+		{
+			"quoteExactInputSingle((address tokenIn, address tokenOut, uint256 amountIn, uint24 fee, uint160 sqrtPriceLimitX96) memory params)(uint256 amountOut)",
+			"quoteExactInputSingle((address,address,uint256,uint24,uint160))", "(uint256)", []string{"amountOut"}, "view",
+		},
+		{
+			"calculateNextMulticall(uint128)(address[] pools, tuple(uint128 index, uint128 indexNext, uint128 indexEnd)[7] indexPerOperations)",
+			"calculateNextMulticall(uint128)", "(address[],(uint128,uint128,uint128)[7])", []string{"pools", "indexPerOperations"}, "view",
+		},
+		{
+			"function aggregate3((address,bool,bytes)[] calldata calls) payable returns ((bool success, bytes returnData)[] returnData)",
+			"aggregate3((address,bool,bytes)[])", "((bool,bytes)[])", []string{"returnData"}, "payable",
+		},
+		{"nested(((uint256,bool)[2],string)[][3])(bytes32)", "nested(((uint256,bool)[2],string)[][3])", "(bytes32)", []string{""}, "view"},
+	}
+	for _, test := range tests {
+		t.Run(test.signature, func(t *testing.T) {
+			sig, err := ParseSignature(test.signature)
+			require.NoError(t, err)
+			method := sig.Method()
+			require.Equal(t, test.sig, method.Sig)
+			require.Equal(t, test.mutability, sig.StateMutability)
+			outputs := make([]string, len(sig.Outputs))
+			names := make([]string, len(sig.Outputs))
+			for i, output := range sig.Outputs {
+				outputs[i] = output.Type.String()
+				names[i] = output.Name
+			}
+			require.Equal(t, test.outputs, "("+strings.Join(outputs, ",")+")")
+			require.Equal(t, test.names, names)
+		})
+	}
+}
+
+func TestParseSignature_Error(t *testing.T) {
+	tests := []struct {
+		signature string
+		pos       int
+		msg       string
+	}{
+		{"", 0, `expected method name, found end of signature`},
+		{"balanceOf(address", 17, `expected "," or ")", found end of signature`},
+		{"balanceOf(adress)(uint256)", 10, `unknown type "adress"`},
+		{"balanceOf(address)(uint256", 26, `expected "," or ")", found end of signature`},
+		{"balanceOf(address)(uint256) view view", 33, `duplicate state mutability "view"`},
+		{"balanceOf(address)(uint256) foo", 28, `unexpected "foo"`},
+		{"get(uint256[0])", 12, `invalid array size "0"`},
+		{"get(uint256[2)", 13, `expected "]", found ")"`},
+		{"get(uint256;)", 11, `unexpected character ';'`},
+		{"get(,)", 4, `expected type, found ","`},
+		{"function get() returns uint256", 23, `expected "(" after returns, found "uint256"`},
+	}
+	for _, test := range tests {
+		t.Run(test.signature, func(t *testing.T) {
+			_, err := ParseSignature(test.signature)
+			var sigErr *SignatureError
+			require.True(t, errors.As(err, &sigErr), "%v", err)
+			require.Equal(t, test.pos, sigErr.Pos)
+			require.Equal(t, test.msg, sigErr.Msg)
+		})
+	}
+}
+
+func TestSignatureViewCall_Tuple(t *testing.T) {
+	call := NewViewCall(common.Address{}, "quote((address token, uint256 amount) params)((uint256 amountOut, bool ok) result)", []interface{}{
+		struct {
+			Token  common.Address
+			Amount *big.Int
+		}{common.HexToAddress("0x01"), big.NewInt(2)},
+	}, nil)
+	callData, err := call.CallData()
+	require.NoError(t, err)
+	require.Len(t, callData, 4+2*32)
+	require.Equal(t, big.NewInt(2), new(big.Int).SetBytes(callData[4+32:]))
+
+	returnValues, err := call.Decode(append(common.LeftPadBytes([]byte{7}, 32), common.LeftPadBytes([]byte{1}, 32)...))
+	require.NoError(t, err)
+	require.Len(t, returnValues, 1)
+	result := returnValues[0].(struct {
+		AmountOut *big.Int `json:"amountOut"`
+		Ok        bool     `json:"ok"`
+	})
+	require.Equal(t, big.NewInt(7), result.AmountOut)
+	require.True(t, result.Ok)
+
+	// outputs of calls with a custom decoder may use any type name
+	call = NewViewCallWithDecoder(common.Address{}, "calculateNextMulticall(uint128)(address[],IndexPerOperation[7])", []interface{}{big.NewInt(10)}, func(raw []byte) ([]interface{}, error) {
+		return nil, nil
+	}, nil)
+	_, err = call.CallData()
+	require.NoError(t, err)
+}
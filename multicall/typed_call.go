@@ -0,0 +1,115 @@
+package multicall
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"reflect"
+)
+
+// outputsProvider is implemented by view calls which know their return arguments
+type outputsProvider interface {
+	outputs() (abi.Arguments, error)
+}
+
+// TypedCall is a ViewCall which decodes its return values into T.
+//
+// A single return value is converted to T directly, e.g. *big.Int, string or a struct
+// for a tuple. Multiple return values are copied into the fields of struct T, by
+// argument name (honoring `abi:"name"` tags) if all return values are named,
+// otherwise by position of the exported fields.
+type TypedCall[T any] struct {
+	ViewCall
+	callback func(value T, err error)
+}
+
+func NewTypedCall[T any](target common.Address, method string, arguments []interface{}, callback func(value T, err error)) ViewCall {
+	return newTypedCall(NewViewCall(target, method, arguments, nil), callback)
+}
+
+func NewTypedCallWithAllowFailure[T any](target common.Address, method string, arguments []interface{}, callback func(value T, err error), allowFailure bool) ViewCall {
+	return newTypedCall(NewViewCallWithAllowFailure(target, method, arguments, nil, allowFailure), callback)
+}
+
+func NewTypedCallWithGasLimit[T any](target common.Address, method string, arguments []interface{}, callback func(value T, err error), gasLimit *big.Int) ViewCall {
+	return newTypedCall(NewViewCallWithGasLimit(target, method, arguments, nil, gasLimit), callback)
+}
+
+func NewTypedABICall[T any](target common.Address, method abi.Method, arguments []interface{}, callback func(value T, err error)) ViewCall {
+	return newTypedCall(NewABIViewCall(target, method, arguments, nil), callback)
+}
+
+func newTypedCall[T any](call ViewCall, callback func(value T, err error)) *TypedCall[T] {
+	return &TypedCall[T]{
+		ViewCall: call,
+		callback: callback,
+	}
+}
+
+// EstimatedReturnSize forwards the estimate of the embedded call, which the embedding interface hides
+func (call *TypedCall[T]) EstimatedReturnSize() int {
+	return EstimateReturnSize(call.ViewCall)
+}
+
+// Callback converts the decoded values into T before calling the typed callback.
+// The returned error is the call, decode or conversion error.
+func (call *TypedCall[T]) Callback() func(err error, returnValues []interface{}) error {
+	return func(err error, returnValues []interface{}) error {
+		var value T
+		if err == nil {
+			value, err = call.Convert(returnValues)
+		}
+		if call.callback != nil {
+			call.callback(value, err)
+		}
+		return err
+	}
+}
+
+// Convert converts decoded return values into T
+func (call *TypedCall[T]) Convert(returnValues []interface{}) (value T, err error) {
+	defer func() {
+		// abi.ConvertType panics if the values are not assignable
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cannot convert return values of %s into %T: %v", call.Target(), value, r)
+		}
+	}()
+
+	dst := reflect.ValueOf(&value).Elem()
+	if len(returnValues) == 1 && (dst.Kind() != reflect.Struct || reflect.ValueOf(returnValues[0]).Kind() == reflect.Struct) {
+		abi.ConvertType(returnValues[0], &value)
+		return value, nil
+	}
+	if dst.Kind() != reflect.Struct {
+		return value, fmt.Errorf("cannot convert %d return values of %s into %T, a struct is required", len(returnValues), call.Target(), value)
+	}
+	if provider, ok := call.ViewCall.(outputsProvider); ok {
+		if args, err := provider.outputs(); err == nil && len(args) == len(returnValues) && named(args) {
+			return value, args.Copy(&value, returnValues)
+		}
+	}
+
+	fields := make([]reflect.Value, 0, dst.NumField())
+	for i := 0; i < dst.NumField(); i++ {
+		if dst.Type().Field(i).IsExported() {
+			fields = append(fields, dst.Field(i))
+		}
+	}
+	if len(fields) != len(returnValues) {
+		return value, fmt.Errorf("cannot convert %d return values of %s into %T with %d exported fields", len(returnValues), call.Target(), value, len(fields))
+	}
+	for i, field := range fields {
+		abi.ConvertType(returnValues[i], field.Addr().Interface())
+	}
+	return value, nil
+}
+
+func named(args abi.Arguments) bool {
+	for _, arg := range args {
+		if arg.Name == "" {
+			return false
+		}
+	}
+	return true
+}
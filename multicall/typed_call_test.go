@@ -0,0 +1,85 @@
+package multicall
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hawkneo/utils/multicall/contract"
+	"github.com/stretchr/testify/require"
+)
+
+const slot0ABI = `[{"inputs":[],"name":"slot0","outputs":[{"name":"sqrtPriceX96","type":"uint160"},{"name":"tick","type":"int24"},{"name":"unlocked","type":"bool"}],"stateMutability":"view","type":"function"}]`
+
+type slot0 struct {
+	SqrtPrice *big.Int `abi:"sqrtPriceX96"`
+	Tick      *big.Int
+	Unlocked  bool
+}
+
+func TestTypedCall(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(slot0ABI))
+	require.NoError(t, err)
+	slot0Output, err := parsed.Methods["slot0"].Outputs.Pack(big.NewInt(79228162514264337), big.NewInt(-12), true)
+	require.NoError(t, err)
+	uint256Output := common.LeftPadBytes([]byte{42}, 32)
+
+	backend := &fakeBackend{output: packAggregate3(t, 1,
+		contract.Multicall3Result{Success: true, ReturnData: uint256Output},
+		contract.Multicall3Result{Success: true, ReturnData: slot0Output},
+		contract.Multicall3Result{Success: true, ReturnData: slot0Output},
+		contract.Multicall3Result{Success: true, ReturnData: uint256Output},
+		contract.Multicall3Result{Success: true, ReturnData: uint256Output},
+	)}
+	mc, err := NewMulticall3(backend, common.Address{})
+	require.NoError(t, err)
+
+	var (
+		balance   *big.Int
+		named     slot0
+		positions struct {
+			Price    *big.Int
+			Tick     *big.Int
+			Unlocked bool
+		}
+		wrapped  struct{ Balance *big.Int }
+		wrongErr error
+	)
+	res, err := mc.Call(nil, ViewCalls{
+		NewTypedCall(common.Address{}, "balanceOf(address)(uint256)", []interface{}{common.Address{}}, func(value *big.Int, err error) {
+			require.NoError(t, err)
+			balance = value
+		}),
+		NewTypedABICall(common.Address{}, parsed.Methods["slot0"], []interface{}{}, func(value slot0, err error) {
+			require.NoError(t, err)
+			named = value
+		}),
+		NewTypedCall(common.Address{}, "slot0()(uint160,int24,bool)", []interface{}{}, func(value struct {
+			Price    *big.Int
+			Tick     *big.Int
+			Unlocked bool
+		}, err error) {
+			require.NoError(t, err)
+			positions = value
+		}),
+		NewTypedCall(common.Address{}, "balanceOf(address)(uint256)", []interface{}{common.Address{}}, func(value struct{ Balance *big.Int }, err error) {
+			require.NoError(t, err)
+			wrapped = value
+		}),
+		NewTypedCall(common.Address{}, "balanceOf(address)(uint256)", []interface{}{common.Address{}}, func(value string, err error) {
+			wrongErr = err
+		}),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, big.NewInt(42), balance)
+	require.Equal(t, big.NewInt(42), wrapped.Balance)
+	require.Equal(t, slot0{SqrtPrice: big.NewInt(79228162514264337), Tick: big.NewInt(-12), Unlocked: true}, named)
+	require.Equal(t, named.SqrtPrice, positions.Price)
+	require.Equal(t, named.Tick, positions.Tick)
+	require.True(t, positions.Unlocked)
+	require.Error(t, wrongErr)
+	require.Error(t, res.Calls[4].Error)
+}
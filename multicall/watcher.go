@@ -0,0 +1,273 @@
+package multicall
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/hawkneo/utils/task"
+	"math/big"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultWatchBufferSize   = 16
+	DefaultWatchPollInterval = 4 * time.Second
+
+	// watchHashRetention is the number of blocks whose delivered hash is kept to detect reorgs
+	watchHashRetention = 256
+)
+
+// HeadSubscriber subscribes to new chain heads, ethclient.Client and the simulated backend satisfy it.
+type HeadSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// WatchResult is the Result of the watched calls pinned to Header, or the error which stopped the block.
+// Reorged is set if a different block of the same number was delivered before.
+type WatchResult struct {
+	Header  *types.Header
+	Result  *Result
+	Err     error
+	Reorged bool
+}
+
+type WatchOption func(*Watcher)
+
+// Watcher executes the same ViewCalls pinned to every new block and delivers the results on a channel.
+//
+// Each block is delivered once per block hash. If the chain reorganizes, the blocks of the new branch are
+// delivered again with WatchResult.Reorged set. Blocks skipped between two heads are caught up in order.
+//
+// The channel is buffered, once it is full the Watcher waits for the consumer and coalesces the heads
+// arriving in the meantime, so a slow consumer delays the results but never loses a block.
+type Watcher struct {
+	caller        Caller
+	chain         HeaderReader
+	confirmations uint64
+	pollInterval  time.Duration
+	results       chan *WatchResult
+
+	mu    sync.RWMutex
+	calls ViewCalls
+
+	hashes  map[uint64]common.Hash // delivered block hash by number
+	next    uint64                 // next block number to deliver
+	started bool                   // next is set by the first head
+}
+
+// NewWatcher creates a Watcher of calls, heads are read from chain
+func NewWatcher(caller Caller, chain HeaderReader, calls ViewCalls, opts ...WatchOption) *Watcher {
+	w := &Watcher{
+		caller:  caller,
+		chain:   chain,
+		calls:   calls,
+		results: make(chan *WatchResult, DefaultWatchBufferSize),
+		hashes:  make(map[uint64]common.Hash),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// WithConfirmations delays every block until n blocks are built on top of it
+func WithConfirmations(n uint64) WatchOption {
+	return func(w *Watcher) {
+		w.confirmations = n
+	}
+}
+
+// WithPollInterval polls the latest header with a task.Task instead of subscribing to new heads.
+// Chains which are not a HeadSubscriber are polled every DefaultWatchPollInterval.
+func WithPollInterval(interval time.Duration) WatchOption {
+	return func(w *Watcher) {
+		w.pollInterval = interval
+	}
+}
+
+// WithBufferSize sets the number of results buffered before the Watcher waits for the consumer
+func WithBufferSize(n int) WatchOption {
+	return func(w *Watcher) {
+		w.results = make(chan *WatchResult, n)
+	}
+}
+
+// Results returns the channel of results, it is closed when Run returns
+func (w *Watcher) Results() <-chan *WatchResult {
+	return w.results
+}
+
+// SetCalls replaces the watched calls from the next block on
+func (w *Watcher) SetCalls(calls ViewCalls) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.calls = calls
+}
+
+// Run watches the chain until ctx is done or the subscription fails. It can only be run once.
+func (w *Watcher) Run(ctx context.Context) error {
+	defer close(w.results)
+
+	if subscriber, ok := w.chain.(HeadSubscriber); ok && w.pollInterval == 0 {
+		return w.subscribe(ctx, subscriber)
+	}
+	return w.poll(ctx)
+}
+
+func (w *Watcher) subscribe(ctx context.Context, subscriber HeadSubscriber) error {
+	heads := make(chan *types.Header, DefaultWatchBufferSize)
+	sub, err := subscriber.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return fmt.Errorf("watcher: subscribe new head error: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	// the latest head is delivered right away instead of with the next block
+	head, err := w.chain.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("watcher: get latest header error: %w", err)
+	}
+	if err := w.process(ctx, head); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			return fmt.Errorf("watcher: subscription error: %w", err)
+		case head := <-heads:
+			// the blocks between two heads are caught up, so only the latest head matters
+			for pending := len(heads); pending > 0; pending-- {
+				head = <-heads
+			}
+			if err := w.process(ctx, head); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (w *Watcher) poll(ctx context.Context) error {
+	interval := w.pollInterval
+	if interval <= 0 {
+		interval = DefaultWatchPollInterval
+	}
+	t := task.NewTask(interval, func() {
+		head, err := w.chain.HeaderByNumber(ctx, nil)
+		if err != nil {
+			_ = w.send(ctx, &WatchResult{Err: fmt.Errorf("watcher: get latest header error: %w", err)})
+			return
+		}
+		_ = w.process(ctx, head)
+	}, task.WithContext(ctx), task.WithName("multicall watcher"), task.WithInitialDelay(0))
+	t.RunAndClose()
+	return ctx.Err()
+}
+
+// process delivers the blocks up to the confirmed block of head which were not delivered yet.
+// Errors of a block are delivered, the block is retried with the next head.
+// Only an error of ctx is returned.
+func (w *Watcher) process(ctx context.Context, head *types.Header) error {
+	if head.Number.Uint64() < w.confirmations {
+		return nil
+	}
+	target := head.Number.Uint64() - w.confirmations
+
+	// the cursor starts at the first head and only advances with the delivered blocks,
+	// so a block which fails is retried with the next head
+	if !w.started {
+		w.next, w.started = target, true
+	}
+	from := w.next
+	if target < from {
+		from = target + 1
+	}
+	// walk back over the delivered blocks which are no longer canonical
+	for n := from - 1; ; n-- {
+		hash, ok := w.hashes[n]
+		if !ok {
+			break
+		}
+		header, err := w.header(ctx, head, n)
+		if err != nil {
+			return w.send(ctx, &WatchResult{Err: err})
+		}
+		if header.Hash() == hash {
+			break
+		}
+		from = n
+		if n == 0 {
+			break
+		}
+	}
+
+	for n := from; n <= target; n++ {
+		header, err := w.header(ctx, head, n)
+		if err != nil {
+			return w.send(ctx, &WatchResult{Err: err})
+		}
+		hash, delivered := w.hashes[n]
+		if delivered && hash == header.Hash() {
+			continue
+		}
+		result, err := w.call(ctx, header)
+		if err != nil {
+			return w.send(ctx, &WatchResult{Header: header, Err: err})
+		}
+		if err := w.send(ctx, &WatchResult{Header: header, Result: result, Reorged: delivered}); err != nil {
+			return err
+		}
+		w.hashes[n] = header.Hash()
+		w.next = n + 1
+	}
+	w.next = target + 1
+
+	for n := range w.hashes {
+		if n+watchHashRetention <= target {
+			delete(w.hashes, n)
+		}
+	}
+	return nil
+}
+
+func (w *Watcher) header(ctx context.Context, head *types.Header, number uint64) (*types.Header, error) {
+	if head.Number.Uint64() == number {
+		return head, nil
+	}
+	header, err := w.chain.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, fmt.Errorf("watcher: get header %d error: %w", number, err)
+	}
+	return header, nil
+}
+
+func (w *Watcher) call(ctx context.Context, header *types.Header) (*Result, error) {
+	w.mu.RLock()
+	calls := w.calls
+	w.mu.RUnlock()
+
+	result, err := w.caller.Call(&bind.CallOpts{Context: ctx, BlockNumber: header.Number}, calls)
+	if err != nil {
+		return nil, fmt.Errorf("watcher: call at block %d error: %w", header.Number, err)
+	}
+	// Multicall3 returns the hash of the current block as zero and AggregateMulticall none
+	if result.BlockHash == (common.Hash{}) {
+		result.BlockHash = header.Hash()
+	}
+	return result, nil
+}
+
+func (w *Watcher) send(ctx context.Context, result *WatchResult) error {
+	select {
+	case w.results <- result:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,131 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChain is a canonical chain of headers, a fork replaces the headers from a number on
+type fakeChain struct {
+	headers []*types.Header
+}
+
+func (c *fakeChain) extend(n int, fork byte) {
+	for i := 0; i < n; i++ {
+		header := &types.Header{Number: big.NewInt(int64(len(c.headers))), Extra: []byte{fork}}
+		if len(c.headers) > 0 {
+			header.ParentHash = c.headers[len(c.headers)-1].Hash()
+		}
+		c.headers = append(c.headers, header)
+	}
+}
+
+func (c *fakeChain) head() *types.Header {
+	return c.headers[len(c.headers)-1]
+}
+
+func (c *fakeChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return c.head(), nil
+	}
+	return c.headers[number.Int64()], nil
+}
+
+// blockCaller returns an empty result at the pinned block
+type blockCaller struct{}
+
+func (blockCaller) Call(opts *bind.CallOpts, calls ViewCalls) (*Result, error) {
+	return &Result{BlockNumber: opts.BlockNumber.Uint64()}, nil
+}
+
+// failingBlockCaller fails the first calls, then returns an empty result at the pinned block
+type failingBlockCaller struct {
+	failures int
+}
+
+func (c *failingBlockCaller) Call(opts *bind.CallOpts, calls ViewCalls) (*Result, error) {
+	if c.failures > 0 {
+		c.failures--
+		return nil, errors.New("timeout")
+	}
+	return blockCaller{}.Call(opts, calls)
+}
+
+func requireDelivered(t *testing.T, w *Watcher, chain *fakeChain, reorged bool, numbers ...uint64) {
+	for _, number := range numbers {
+		result := <-w.Results()
+		require.NoError(t, result.Err)
+		require.Equal(t, number, result.Result.BlockNumber)
+		require.Equal(t, chain.headers[number].Hash(), result.Header.Hash())
+		require.Equal(t, chain.headers[number].Hash(), result.Result.BlockHash)
+		require.Equal(t, reorged, result.Reorged)
+	}
+}
+
+func TestWatcher_Process(t *testing.T) {
+	ctx := context.Background()
+	chain := &fakeChain{}
+	chain.extend(6, 0)
+	w := NewWatcher(blockCaller{}, chain, nil, WithConfirmations(2), WithBufferSize(32))
+
+	// starts at the confirmed block of the first head
+	require.NoError(t, w.process(ctx, chain.head()))
+	requireDelivered(t, w, chain, false, 3)
+	require.NoError(t, w.process(ctx, chain.head()))
+	require.Empty(t, w.Results())
+
+	// skipped heads are caught up
+	chain.extend(3, 0)
+	require.NoError(t, w.process(ctx, chain.head()))
+	requireDelivered(t, w, chain, false, 4, 5, 6)
+
+	// a reorg of the confirmed blocks delivers the new branch again
+	chain.headers = chain.headers[:5]
+	chain.extend(5, 1)
+	require.NoError(t, w.process(ctx, chain.head()))
+	requireDelivered(t, w, chain, true, 5, 6)
+	requireDelivered(t, w, chain, false, 7)
+	require.Empty(t, w.Results())
+	chain.extend(1, 1)
+	require.NoError(t, w.process(ctx, chain.head()))
+	requireDelivered(t, w, chain, false, 8)
+
+	// a shorter branch does not deliver the unconfirmed blocks
+	chain.headers = chain.headers[:8]
+	chain.extend(1, 2)
+	require.NoError(t, w.process(ctx, chain.head()))
+	require.Empty(t, w.Results())
+	chain.extend(3, 2)
+	require.NoError(t, w.process(ctx, chain.head()))
+	requireDelivered(t, w, chain, true, 8)
+	requireDelivered(t, w, chain, false, 9)
+	require.Empty(t, w.Results())
+
+	// not enough blocks for a confirmed block
+	w = NewWatcher(blockCaller{}, &fakeChain{}, nil, WithConfirmations(2))
+	require.NoError(t, w.process(ctx, &types.Header{Number: big.NewInt(1)}))
+	require.Empty(t, w.Results())
+	require.Empty(t, w.hashes)
+}
+
+func TestWatcher_ProcessFirstHeadError(t *testing.T) {
+	ctx := context.Background()
+	chain := &fakeChain{}
+	chain.extend(4, 0)
+	w := NewWatcher(&failingBlockCaller{failures: 1}, chain, nil, WithBufferSize(32))
+
+	// the failed first block is retried with the next head
+	require.NoError(t, w.process(ctx, chain.head()))
+	result := <-w.Results()
+	require.EqualError(t, result.Err, "watcher: call at block 3 error: timeout")
+	chain.extend(1, 0)
+	require.NoError(t, w.process(ctx, chain.head()))
+	requireDelivered(t, w, chain, false, 3, 4)
+	require.Empty(t, w.Results())
+}
@@ -0,0 +1,168 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/hawkneo/utils/multicall/contract"
+	"math/big"
+)
+
+var (
+	_ TransactionTracer = (*RPCCaller)(nil)
+
+	ErrTransactUnsupported = errors.New("multicall3: building a transaction requires a bind.ContractTransactor backend")
+	ErrTraceUnsupported    = errors.New("multicall3: decoding a transaction requires a TransactionTracer backend")
+	ErrTransactionFailed   = errors.New("multicall3: transaction failed")
+)
+
+// TransactionTracer returns the return data of a mined transaction
+type TransactionTracer interface {
+	TransactionOutput(ctx context.Context, txHash common.Hash) ([]byte, error)
+}
+
+// WriteCall is a state-changing call of a Multicall3 transaction, executed with aggregate3Value.
+// Value is sent from Multicall3 to Target, nil means no value.
+type WriteCall struct {
+	Target       common.Address
+	CallData     []byte
+	Value        *big.Int
+	AllowFailure bool
+}
+
+// NewWriteCall packs the arguments of the method signature into the call data of a WriteCall
+func NewWriteCall(target common.Address, method string, arguments []interface{}, value *big.Int, allowFailure bool) (WriteCall, error) {
+	sig, err := parseSignature(method, false)
+	if err != nil {
+		return WriteCall{}, err
+	}
+	if len(sig.Inputs) != len(arguments) {
+		return WriteCall{}, fmt.Errorf("number of argument types doesn't match with number of arguments with method %s", method)
+	}
+	args, err := sig.Inputs.Pack(arguments...)
+	if err != nil {
+		return WriteCall{}, err
+	}
+	return WriteCall{
+		Target:       target,
+		CallData:     append(append([]byte{}, sig.Method().ID...), args...),
+		Value:        value,
+		AllowFailure: allowFailure,
+	}, nil
+}
+
+// WriteResult is the outcome of a WriteCall, Error is a *RevertError if the call failed
+type WriteResult struct {
+	Call       WriteCall
+	Success    bool
+	ReturnData []byte
+	Error      error
+}
+
+// BuildTransaction encodes calls into an aggregate3Value transaction from opts.From and returns it unsigned,
+// with the value set to the sum of the values of calls. Nonce, gas price and gas limit are filled in from the
+// backend unless set in opts, the gas is estimated so a call which does not allow failure and reverts
+// fails here. opts.Value, opts.Signer and opts.NoSend are ignored.
+//
+// The backend must be a bind.ContractTransactor, like *ethclient.Client.
+func (mc *Multicall3) BuildTransaction(opts *bind.TransactOpts, calls []WriteCall) (*types.Transaction, *WriteReceiptDecoder, error) {
+	backend, ok := mc.backend.(bind.ContractTransactor)
+	if !ok {
+		return nil, nil, ErrTransactUnsupported
+	}
+	if opts == nil {
+		return nil, nil, fmt.Errorf("multicall3: transact opts are required")
+	}
+	transactor, err := contract.NewMulticall3Transactor(mc.address, backend)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	value := new(big.Int)
+	callValues := make([]contract.Multicall3Call3Value, 0, len(calls))
+	for _, call := range calls {
+		callValue := call.Value
+		if callValue == nil {
+			callValue = new(big.Int)
+		}
+		value.Add(value, callValue)
+		callValues = append(callValues, contract.Multicall3Call3Value{
+			Target:       call.Target,
+			AllowFailure: call.AllowFailure,
+			Value:        callValue,
+			CallData:     call.CallData,
+		})
+	}
+
+	txOpts := *opts
+	txOpts.Value = value
+	txOpts.NoSend = true
+	txOpts.Signer = func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		return tx, nil
+	}
+	tx, err := transactor.Aggregate3Value(&txOpts, callValues)
+	if err != nil {
+		return nil, nil, fmt.Errorf("multicall3: build transaction error: %w", err)
+	}
+	decoder := &WriteReceiptDecoder{calls: calls, errors: mc.errors}
+	decoder.tracer, _ = mc.backend.(TransactionTracer)
+	return tx, decoder, nil
+}
+
+// WriteReceiptDecoder maps the receipt of a transaction built by BuildTransaction back to its calls
+type WriteReceiptDecoder struct {
+	tracer TransactionTracer
+	calls  []WriteCall
+	errors *abi.ABI
+}
+
+// Decode returns the result of every call of the mined transaction, in the order of the calls.
+//
+// Receipts do not carry return data, so the return data of the transaction is read by tracing it, the
+// backend of the Multicall3 must be a TransactionTracer like RPCCaller, otherwise ErrTraceUnsupported is
+// returned. A failed receipt returns ErrTransactionFailed.
+func (d *WriteReceiptDecoder) Decode(ctx context.Context, receipt *types.Receipt) ([]WriteResult, error) {
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("%w: %s", ErrTransactionFailed, receipt.TxHash)
+	}
+	if d.tracer == nil {
+		return nil, ErrTraceUnsupported
+	}
+	output, err := d.tracer.TransactionOutput(ctx, receipt.TxHash)
+	if err != nil {
+		return nil, fmt.Errorf("multicall3: trace transaction %s error: %w", receipt.TxHash, err)
+	}
+	return d.decode(output)
+}
+
+func (d *WriteReceiptDecoder) decode(output []byte) ([]WriteResult, error) {
+	parsed, err := contract.Multicall3MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	values, err := parsed.Unpack("aggregate3Value", output)
+	if err != nil {
+		return nil, err
+	}
+	results := *abi.ConvertType(values[0], new([]contract.Multicall3Result)).(*[]contract.Multicall3Result)
+	if len(results) != len(d.calls) {
+		return nil, fmt.Errorf("multicall3: got %d results for %d calls", len(results), len(d.calls))
+	}
+
+	writeResults := make([]WriteResult, len(d.calls))
+	for index, call := range d.calls {
+		writeResults[index] = WriteResult{
+			Call:       call,
+			Success:    results[index].Success,
+			ReturnData: results[index].ReturnData,
+		}
+		if !results[index].Success {
+			writeResults[index].Error = DecodeRevert(results[index].ReturnData, d.errors)
+		}
+	}
+	return writeResults, nil
+}
@@ -0,0 +1,114 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/hawkneo/utils/multicall/contract"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWriteCall(t *testing.T) {
+	to := common.HexToAddress("0x02")
+	call, err := NewWriteCall(common.HexToAddress("0x01"), "transfer(address,uint256)(bool)", []interface{}{to, big.NewInt(5)}, nil, true)
+	require.NoError(t, err)
+	require.Equal(t, common.FromHex("0xa9059cbb"), call.CallData[:4])
+	require.Equal(t, common.LeftPadBytes(to.Bytes(), 32), call.CallData[4:36])
+	require.Len(t, call.CallData, 68)
+	require.True(t, call.AllowFailure)
+
+	_, err = NewWriteCall(common.Address{}, "transfer(address,uint256)", []interface{}{to}, nil, false)
+	require.Error(t, err)
+}
+
+// fakeTracer returns a fixed output for every transaction
+type fakeTracer struct {
+	output []byte
+	hashes []common.Hash
+}
+
+func (f *fakeTracer) TransactionOutput(ctx context.Context, txHash common.Hash) ([]byte, error) {
+	f.hashes = append(f.hashes, txHash)
+	return f.output, nil
+}
+
+// fakeDebugService answers debug_traceTransaction with a fixed call frame
+type fakeDebugService struct {
+	frame  map[string]interface{}
+	config map[string]interface{}
+}
+
+func (s *fakeDebugService) TraceTransaction(ctx context.Context, txHash common.Hash, config map[string]interface{}) (map[string]interface{}, error) {
+	s.config = config
+	return s.frame, nil
+}
+
+func TestWriteReceiptDecoder_Decode(t *testing.T) {
+	stringType, _ := abi.NewType("string", "", nil)
+	reason, err := abi.Arguments{{Type: stringType}}.Pack("ERC20: insufficient balance")
+	require.NoError(t, err)
+	parsed, err := contract.Multicall3MetaData.GetAbi()
+	require.NoError(t, err)
+	output, err := parsed.Methods["aggregate3Value"].Outputs.Pack([]contract.Multicall3Result{
+		{Success: true, ReturnData: common.LeftPadBytes([]byte{1}, 32)},
+		{Success: false, ReturnData: append(append([]byte{}, errorSelector...), reason...)},
+	})
+	require.NoError(t, err)
+
+	calls := []WriteCall{{Target: common.HexToAddress("0x01")}, {Target: common.HexToAddress("0x02"), AllowFailure: true}}
+	tracer := &fakeTracer{output: output}
+	decoder := &WriteReceiptDecoder{tracer: tracer, calls: calls}
+	txHash := common.HexToHash("0x1234")
+	results, err := decoder.Decode(context.Background(), &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: txHash})
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{txHash}, tracer.hashes)
+	require.Len(t, results, 2)
+	require.True(t, results[0].Success)
+	require.NoError(t, results[0].Error)
+	require.Equal(t, calls[1], results[1].Call)
+	require.False(t, results[1].Success)
+	var revertErr *RevertError
+	require.True(t, errors.As(results[1].Error, &revertErr))
+	require.Equal(t, "ERC20: insufficient balance", revertErr.Reason)
+
+	_, err = decoder.Decode(context.Background(), &types.Receipt{Status: types.ReceiptStatusFailed})
+	require.ErrorIs(t, err, ErrTransactionFailed)
+
+	decoder.calls = calls[:1]
+	_, err = decoder.Decode(context.Background(), &types.Receipt{Status: types.ReceiptStatusSuccessful})
+	require.Error(t, err)
+
+	decoder.tracer = nil
+	_, err = decoder.Decode(context.Background(), &types.Receipt{Status: types.ReceiptStatusSuccessful})
+	require.ErrorIs(t, err, ErrTraceUnsupported)
+}
+
+func TestRPCCaller_TransactionOutput(t *testing.T) {
+	service := &fakeDebugService{frame: map[string]interface{}{"output": "0x0102"}}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("debug", service))
+	caller := NewRPCCaller(rpc.DialInProc(server))
+
+	output, err := caller.TransactionOutput(context.Background(), common.HexToHash("0x1234"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2}, output)
+	require.Equal(t, "callTracer", service.config["tracer"])
+
+	service.frame = map[string]interface{}{"output": "0x", "error": "execution reverted"}
+	_, err = caller.TransactionOutput(context.Background(), common.HexToHash("0x1234"))
+	require.ErrorContains(t, err, "execution reverted")
+}
+
+func TestMulticall3_BuildTransactionUnsupported(t *testing.T) {
+	mc, err := NewMulticall3(&fakeBackend{}, common.Address{})
+	require.NoError(t, err)
+	_, _, err = mc.BuildTransaction(&bind.TransactOpts{}, nil)
+	require.ErrorIs(t, err, ErrTransactUnsupported)
+}
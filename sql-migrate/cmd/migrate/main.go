@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"database/sql"
 	"encoding/json"
@@ -10,6 +11,7 @@ import (
 	"github.com/hawkneo/utils/sql-migrate"
 	_ "github.com/lib/pq"
 	"github.com/spf13/cobra"
+	_ "modernc.org/sqlite"
 	"os"
 	"path"
 	"path/filepath"
@@ -23,12 +25,12 @@ type Config struct {
 	SchemaName string `json:"schema_name"`
 	// Dialect is the database dialect used to generate the SQL statements.
 	//
-	// Support dialects are: postgres, mysql.
+	// Support dialects are: postgres, mysql, sqlite.
 	//
 	// If not set, the default dialect is "postgres".
 	Dialect string `json:"dialect"`
 	// DataSourceName is the database connection string.
-	// See sql.Open for details.
+	// See sql.Open for details, e.g. "file:app.db" for sqlite.
 	DataSourceName string `json:"data_source_name"`
 	// WorkingDirectory is the working directory.
 	WorkingDirectory string `json:"working_directory"`
@@ -39,6 +41,9 @@ type Config struct {
 	DisableColorOutput bool `json:"disable_color_output"`
 	// LoggerLevel is the level of the logger.
 	LoggerLevel string `json:"logger_level"`
+	// LockTimeout is the time to wait for the migration lock held by another migrator, e.g. "30s".
+	// If not set, the default lock timeout is 1 minute.
+	LockTimeout string `json:"lock_timeout"`
 
 	// MigrationSource is the directory containing the migration files.
 	// 	If not set, the default directory is "migrations".
@@ -46,6 +51,9 @@ type Config struct {
 	// The migration files must be named in the following format:
 	// 	V<version>__<description>.sql, where <version> is the version number and <description> is a short description of the migration.
 	// 	The version number can be any positive number or text, but it must be unique.
+	// 	Versions are compared segment by segment, split on "." and "_", numeric segments numerically,
+	// 	e.g. 2.9 < 2.10 < 10, and timestamps like 20221125112000 are plain numbers.
+	// 	Ambiguous versions like 1 and 1.0 are rejected.
 	// 	The description can be any text. It is recommended to use a short description of the migration.
 	// 	For example:
 	//	- V1__Create_users_table.sql
@@ -53,6 +61,9 @@ type Config struct {
 	//	- V2.1__Add_email_index.sql
 	//	- V2.2__Add_email_unique_constraint.sql
 	//	- V3__Add_password_column.sql
+	//
+	// Repeatable migrations are named R__<description>.sql, e.g. R__Create_users_view.sql. They are applied after
+	// the versioned migrations, again whenever their content changes, and are not rolled back.
 	MigrationSource string `json:"migration_source"`
 }
 
@@ -63,13 +74,17 @@ func main() {
 
 	configPath := rootCmd.PersistentFlags().StringP("config", "c", "migration_config.json", "config file in json format")
 	workingDirectory := rootCmd.PersistentFlags().StringP("working-dir", "w", "", "working directory")
-	var migrateCtx *migrate.Context
+	var (
+		migrateCtx *migrate.Context
+		// offline is set by the commands which do not connect to the database
+		offline bool
+	)
 	preRunE := func(cmd *cobra.Command, args []string) error {
 		conf, err := readConfig(workingDirectory, configPath)
 		if err != nil {
 			return err
 		}
-		migrateConf, err := convertToMigrateConfig(conf)
+		migrateConf, err := convertToMigrateConfig(conf, offline)
 		if err != nil {
 			return err
 		}
@@ -119,6 +134,34 @@ func main() {
 		},
 	})
 
+	{
+		validateCmd := &cobra.Command{
+			Use:   "validate",
+			Short: "Validate the migration files, and the migration history unless --offline is set",
+			Long: strings.TrimSpace(fmt.Sprintf(`
+Exits with a non-zero code if a problem is found, e.g. in CI.
+
+Example:
+  Validate the migration files without connecting to the database:
+  $ %s validate --offline
+  Validate the migration files and the migration history:
+  $ %s validate
+`, os.Args[0], os.Args[0],
+			)),
+			PreRunE: preRunE,
+		}
+		validateCmd.Flags().BoolVar(&offline, "offline", false, "Validate the migration files without connecting to the database")
+		validateCmd.RunE = func(cmd *cobra.Command, args []string) error {
+			migrator, err := migrate.NewValidateMigrator(migrateCtx)
+			if err != nil {
+				return err
+			}
+
+			return migrator.Apply()
+		}
+		rootCmd.AddCommand(validateCmd)
+	}
+
 	bindDryRunFlagFn := func(cmd *cobra.Command) *bool {
 		return cmd.Flags().Bool("dry-run", false, "Print the SQL statements that will be executed without executing them")
 	}
@@ -178,6 +221,65 @@ Example:
 		rootCmd.AddCommand(downCmd)
 	}
 
+	{
+		repairCmd := &cobra.Command{
+			Use:   "repair",
+			Short: "Realign the migration history with the migration files",
+			Long: strings.TrimSpace(fmt.Sprintf(`
+Replaces the hash and filename of the applied migrations with the ones of their current files,
+e.g. after their line endings or comments are changed, and deletes the rows of the migrations
+without file. The migrations are not applied again.
+
+Example:
+  Show the changes without making them:
+  $ %s repair --dry-run
+  Make the changes without confirmation:
+  $ %s repair --yes
+`, os.Args[0], os.Args[0],
+			)),
+			PreRunE: preRunE,
+		}
+		dryRunFlag := bindDryRunFlagFn(repairCmd)
+		yesFlag := repairCmd.Flags().BoolP("yes", "y", false, "Make the changes without confirmation")
+		repairCmd.RunE = func(cmd *cobra.Command, args []string) error {
+			migrator, err := migrate.NewRepairMigrator(migrateCtx, func(changes []migrate.RepairChange) bool {
+				if *yesFlag {
+					return true
+				}
+				fmt.Printf("Apply %d change(s) to %s? [y/N] ", len(changes), migrateCtx.Conf.SchemaName)
+				answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				answer = strings.ToLower(strings.TrimSpace(answer))
+				return answer == "y" || answer == "yes"
+			})
+			if err != nil {
+				return err
+			}
+			migrateCtx.Conf.DryRun = *dryRunFlag
+
+			return migrator.Apply()
+		}
+		rootCmd.AddCommand(repairCmd)
+	}
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "unlock",
+		Short: "Release the migration lock left by a crashed migrator",
+		Long: strings.TrimSpace(`
+Deletes the row of the lock table whoever holds it. Only run it when the holder named by the
+lock timeout error is known to be dead, or two migrators would run at the same time.
+The Postgres and MySQL locks are released with the session of their holder, they only use a lock
+table if the database is limited to one open connection.`),
+		PreRunE: preRunE,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			migrator, err := migrate.NewUnlockMigrator(migrateCtx)
+			if err != nil {
+				return err
+			}
+
+			return migrator.Apply()
+		},
+	})
+
 	newCmd := &cobra.Command{
 		Use:   "new",
 		Short: "Create a new migration file or a new migration config file",
@@ -273,6 +375,7 @@ Example:
 				MigrateOutOfOrder:  false,
 				DisableColorOutput: false,
 				LoggerLevel:        "info",
+				LockTimeout:        "1m",
 				MigrationSource:    "migrations",
 			}
 			bz, err := json.MarshalIndent(&config, "", "  ")
@@ -334,10 +437,6 @@ func readConfig(workingDirectoryPtr, configPathPtr *string) (*Config, error) {
 		config.Dialect = "postgres"
 	}
 
-	if config.DataSourceName == "" {
-		return nil, fmt.Errorf("data_source_name must be set")
-	}
-
 	if config.MigrationSource == "" {
 		config.MigrationSource = "migrations"
 	}
@@ -345,7 +444,8 @@ func readConfig(workingDirectoryPtr, configPathPtr *string) (*Config, error) {
 	return &config, nil
 }
 
-func convertToMigrateConfig(conf *Config) (*migrate.Config, error) {
+// convertToMigrateConfig converts conf, the database is not opened if offline is set
+func convertToMigrateConfig(conf *Config, offline bool) (*migrate.Config, error) {
 	migrateConf := &migrate.Config{
 		SchemaName:        conf.SchemaName,
 		MigrateOutOfOrder: conf.MigrateOutOfOrder,
@@ -355,6 +455,13 @@ func convertToMigrateConfig(conf *Config) (*migrate.Config, error) {
 		},
 		MigrationSource: migrate.DirectoryMigrationSource{Directory: path.Join(conf.WorkingDirectory, conf.MigrationSource)},
 	}
+	if conf.LockTimeout != "" {
+		lockTimeout, err := time.ParseDuration(conf.LockTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lock_timeout: %w", err)
+		}
+		migrateConf.LockTimeout = lockTimeout
+	}
 
 	var driverName string
 	switch conf.Dialect {
@@ -364,10 +471,19 @@ func convertToMigrateConfig(conf *Config) (*migrate.Config, error) {
 	case "mysql":
 		migrateConf.Dialect = migrate.MySQLDialect{}
 		driverName = "mysql"
+	case "sqlite":
+		migrateConf.Dialect = migrate.SQLiteDialect{}
+		driverName = "sqlite"
 	default:
 		return nil, fmt.Errorf("unsupported dialect: %s", conf.Dialect)
 	}
 
+	if offline {
+		return migrateConf, nil
+	}
+	if conf.DataSourceName == "" {
+		return nil, fmt.Errorf("data_source_name must be set")
+	}
 	db, err := sql.Open(driverName, conf.DataSourceName)
 	if err != nil {
 		return nil, err
@@ -3,6 +3,7 @@ package migrate
 import (
 	"database/sql"
 	"github.com/hawkneo/utils/log"
+	"time"
 )
 
 type Config struct {
@@ -22,6 +23,12 @@ type Config struct {
 
 	MigrationSource MigrationSource
 
+	// LockTimeout is the time to wait for the migration lock held by another migrator, e.g. another pod
+	// starting at the same time. If not set, DefaultLockTimeout is used.
+	LockTimeout time.Duration
+	// LockHolder identifies this migrator in the migration lock. If not set, the hostname and pid are used.
+	LockHolder string
+
 	// DryRun is a flag that if set to true, will not apply any migrations, but will instead print out what migrations would have been applied.
 	DryRun bool
 }
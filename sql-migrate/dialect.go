@@ -1,19 +1,44 @@
 package migrate
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 )
 
 var (
 	_ Dialect = (*PostgresDialect)(nil)
 	_ Dialect = (*MySQLDialect)(nil)
+	_ Dialect = (*SQLiteDialect)(nil)
+
+	_ ForceUnlocker = (*PostgresDialect)(nil)
+	_ ForceUnlocker = (*MySQLDialect)(nil)
+	_ ForceUnlocker = (*SQLiteDialect)(nil)
 )
 
 type Dialect interface {
 	CreateSchemaSQL(schemaName string) string
 	InsertSchemaSQL(schemaName string) string
 	DeleteSchemaSQL(schemaName string) string
+	// UpdateSchemaSQL sets the filename and hash of the row with the id, in this order
+	UpdateSchemaSQL(schemaName string) string
+	// DeleteSchemaByIDSQL deletes the row with the id
+	DeleteSchemaByIDSQL(schemaName string) string
+	// Lock acquires the migration lock of schemaName for holder, waiting at most timeout for the current holder.
+	// If the lock cannot be acquired in time, the error wraps ErrLockTimeout and names the current holder,
+	// as recorded by its Lock.
+	// unlock releases the lock.
+	Lock(ctx context.Context, db *sql.DB, schemaName, holder string, timeout time.Duration) (unlock func() error, err error)
+}
+
+// ForceUnlocker is implemented by the dialects whose migration lock may not be released when its holder
+// crashes, e.g. the lock table of SQLite
+type ForceUnlocker interface {
+	// ForceUnlock releases the migration lock of schemaName whoever holds it, and returns the holder,
+	// empty if there was no lock to release
+	ForceUnlock(ctx context.Context, db *sql.DB, schemaName string) (holder string, err error)
 }
 
 type PostgresDialect struct {
@@ -46,13 +71,25 @@ DELETE FROM %s WHERE filename = $1
 `, schemaName)
 }
 
+func (PostgresDialect) UpdateSchemaSQL(schemaName string) string {
+	return fmt.Sprintf(`
+UPDATE %s SET filename = $1, hash = $2 WHERE id = $3
+`, schemaName)
+}
+
+func (PostgresDialect) DeleteSchemaByIDSQL(schemaName string) string {
+	return fmt.Sprintf(`
+DELETE FROM %s WHERE id = $1
+`, schemaName)
+}
+
 type MySQLDialect struct{}
 
 func (MySQLDialect) CreateSchemaSQL(schemaName string) string {
 	return strings.TrimSpace(fmt.Sprintf(`
 CREATE TABLE %s (
     id BIGINT NOT NULL PRIMARY KEY AUTO_INCREMENT,
-    version BIGINT NOT NULL DEFAULT 0,
+    version VARCHAR(100) NOT NULL ,
     filename VARCHAR(200) NOT NULL UNIQUE ,
     hash VARCHAR(100) NOT NULL ,
     status VARCHAR(20) NOT NULL ,
@@ -73,3 +110,75 @@ func (MySQLDialect) DeleteSchemaSQL(schemaName string) string {
 DELETE FROM %s WHERE filename = ?
 `, schemaName)
 }
+
+func (MySQLDialect) UpdateSchemaSQL(schemaName string) string {
+	return fmt.Sprintf(`
+UPDATE %s SET filename = ?, hash = ? WHERE id = ?
+`, schemaName)
+}
+
+func (MySQLDialect) DeleteSchemaByIDSQL(schemaName string) string {
+	return fmt.Sprintf(`
+DELETE FROM %s WHERE id = ?
+`, schemaName)
+}
+
+// SQLiteDialect is the dialect of SQLite, e.g. with the pure-Go driver modernc.org/sqlite:
+//
+//	import _ "modernc.org/sqlite"
+//	db, err := sql.Open("sqlite", "file:app.db")
+//
+// SQLite DDL is transactional, so a failed migration is rolled back as a whole like with Postgres.
+//
+// ALTER TABLE only supports RENAME TABLE, RENAME COLUMN, ADD COLUMN and DROP COLUMN (SQLite 3.35+).
+// Other changes, e.g. of a column type or constraint, rebuild the table in the migration:
+//
+//	CREATE TABLE new_users (...);
+//	INSERT INTO new_users SELECT ... FROM users;
+//	DROP TABLE users;
+//	ALTER TABLE new_users RENAME TO users;
+//
+// An in-memory database only lives as long as its connection, limit the pool to one connection:
+//
+//	db, err := sql.Open("sqlite", ":memory:")
+//	db.SetMaxOpenConns(1)
+type SQLiteDialect struct{}
+
+func (SQLiteDialect) CreateSchemaSQL(schemaName string) string {
+	return strings.TrimSpace(fmt.Sprintf(`
+CREATE TABLE %s
+(
+    id INTEGER PRIMARY KEY NOT NULL ,
+    version TEXT NOT NULL ,
+    filename TEXT UNIQUE NOT NULL ,
+    hash TEXT NOT NULL ,
+    status TEXT NOT NULL ,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+`, schemaName,
+	))
+}
+
+func (SQLiteDialect) InsertSchemaSQL(schemaName string) string {
+	return fmt.Sprintf(`
+INSERT INTO %s (id, version, filename, hash, status, created_at) VALUES (?, ?, ?, ?, ?, ?)
+`, schemaName)
+}
+
+func (SQLiteDialect) DeleteSchemaSQL(schemaName string) string {
+	return fmt.Sprintf(`
+DELETE FROM %s WHERE filename = ?
+`, schemaName)
+}
+
+func (SQLiteDialect) UpdateSchemaSQL(schemaName string) string {
+	return fmt.Sprintf(`
+UPDATE %s SET filename = ?, hash = ? WHERE id = ?
+`, schemaName)
+}
+
+func (SQLiteDialect) DeleteSchemaByIDSQL(schemaName string) string {
+	return fmt.Sprintf(`
+DELETE FROM %s WHERE id = ?
+`, schemaName)
+}
@@ -0,0 +1,439 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"github.com/hawkneo/utils/log"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openSQLite(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	// every connection opens its own in-memory database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newSQLiteContext(db *sql.DB, source MigrationSource) *Context {
+	return &Context{
+		Context: context.TODO(),
+		Conf: &Config{
+			Dialect:         SQLiteDialect{},
+			DB:              db,
+			SchemaName:      "migration_schema",
+			Logger:          log.AnsiColorLogger{Level: log.LevelWarn},
+			MigrationSource: source,
+		},
+	}
+}
+
+func sqliteColumns(t *testing.T, db *sql.DB, table string) map[string]string {
+	rows, err := db.Query("SELECT name, type FROM pragma_table_info(?)", table)
+	require.NoError(t, err)
+	defer rows.Close()
+	columns := make(map[string]string)
+	for rows.Next() {
+		var name, typ string
+		require.NoError(t, rows.Scan(&name, &typ))
+		columns[name] = typ
+	}
+	require.NoError(t, rows.Err())
+	return columns
+}
+
+func TestMySQLDialect_VersionColumn(t *testing.T) {
+	// dotted versions like 2.10 would be stored as numbers
+	ddl := MySQLDialect{}.CreateSchemaSQL("migration_schema")
+	require.Contains(t, ddl, "version VARCHAR(100) NOT NULL")
+	require.NotContains(t, ddl, "version BIGINT")
+}
+
+func TestSQLiteDialect(t *testing.T) {
+	db := openSQLite(t)
+	source := DirectoryMigrationSource{Directory: "./test_data/sqlite"}
+
+	migrator, err := NewCreateMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.Equal(t, map[string]string{"id": "INTEGER", "name": "TEXT", "age": "INTEGER", "email": "TEXT"}, sqliteColumns(t, db, "users"))
+	var age int
+	require.NoError(t, db.QueryRow("SELECT age FROM users WHERE id = 1").Scan(&age))
+	require.Equal(t, 30, age)
+
+	ctx := newSQLiteContext(db, source)
+	migrator, err = NewStatusMigrator(ctx)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.Len(t, ctx.ms, 3)
+	for _, ms := range ctx.ms {
+		require.Equal(t, StatusApplied, ms.status)
+		require.False(t, ms.schema.CreatedAt.IsZero())
+	}
+
+	migrator, err = NewDownMigrator(newSQLiteContext(db, source), "1")
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.Equal(t, map[string]string{"id": "INTEGER", "name": "TEXT", "age": "TEXT"}, sqliteColumns(t, db, "users"))
+
+	migrator, err = NewDownMigrator(newSQLiteContext(db, source), "")
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.Empty(t, sqliteColumns(t, db, "users"))
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM migration_schema").Scan(&count))
+	require.Zero(t, count)
+}
+
+func TestSQLiteDialect_TransactionalDDL(t *testing.T) {
+	db := openSQLite(t)
+	source := StringMigrationSource{Migrations: []*Migration{
+		{
+			Filename: "V1__failed.sql",
+			Source: `
+-- +migrate Up
+CREATE TABLE users (id INTEGER PRIMARY KEY NOT NULL);
+INSERT INTO missing_table (id) VALUES (1);
+`,
+		},
+	}}
+
+	migrator, err := NewCreateMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.ErrorContains(t, migrator.Apply(), "no such table: missing_table")
+	// the created table is rolled back with the failed statement
+	require.Empty(t, sqliteColumns(t, db, "users"))
+}
+
+func TestSQLiteDialect_Lock(t *testing.T) {
+	db := openSQLite(t)
+	source := DirectoryMigrationSource{Directory: "./test_data/sqlite"}
+
+	migrator, err := NewCreateMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+
+	unlock, err := SQLiteDialect{}.Lock(context.TODO(), db, "migration_schema", "pod-1", time.Second)
+	require.NoError(t, err)
+
+	ctx := newSQLiteContext(db, source)
+	ctx.Conf.LockHolder = "pod-2"
+	ctx.Conf.LockTimeout = 10 * time.Millisecond
+	migrator, err = NewUpMigrator(ctx)
+	require.NoError(t, err)
+	err = migrator.Apply()
+	require.ErrorIs(t, err, ErrLockTimeout)
+	require.ErrorContains(t, err, "held by pod-1")
+	require.Empty(t, sqliteColumns(t, db, "users"))
+
+	// the lock is released by another holder
+	require.NoError(t, unlock())
+	require.NoError(t, migrator.Apply())
+	require.NotEmpty(t, sqliteColumns(t, db, "users"))
+
+	// the lock is released after Apply
+	unlock, err = SQLiteDialect{}.Lock(context.TODO(), db, "migration_schema", "pod-1", 0)
+	require.NoError(t, err)
+	require.NoError(t, unlock())
+}
+
+func TestUnlockMigrator(t *testing.T) {
+	db := openSQLite(t)
+	source := DirectoryMigrationSource{Directory: "./test_data/sqlite"}
+	unlock := func(dialect Dialect) {
+		ctx := newSQLiteContext(db, source)
+		ctx.Conf.Dialect = dialect
+		migrator, err := NewUnlockMigrator(ctx)
+		require.NoError(t, err)
+		require.NoError(t, migrator.Apply())
+	}
+
+	// not locked yet
+	unlock(SQLiteDialect{})
+
+	// the row of a crashed holder
+	_, err := LockTable(context.TODO(), db, "migration_schema_lock", "migration_schema", "pod-1", time.Second)
+	require.NoError(t, err)
+	_, err = SQLiteDialect{}.Lock(context.TODO(), db, "migration_schema", "pod-2", 10*time.Millisecond)
+	require.ErrorIs(t, err, ErrLockTimeout)
+
+	// a dialect without ForceUnlocker
+	unlock(struct{ Dialect }{SQLiteDialect{}})
+	_, err = SQLiteDialect{}.Lock(context.TODO(), db, "migration_schema", "pod-2", 10*time.Millisecond)
+	require.ErrorIs(t, err, ErrLockTimeout)
+
+	unlock(SQLiteDialect{})
+	release, err := SQLiteDialect{}.Lock(context.TODO(), db, "migration_schema", "pod-2", 10*time.Millisecond)
+	require.NoError(t, err)
+	holder, err := UnlockTable(context.TODO(), db, "migration_schema_lock")
+	require.NoError(t, err)
+	require.Contains(t, holder, "pod-2 (since ")
+	require.NoError(t, release())
+	holder, err = UnlockTable(context.TODO(), db, "migration_schema_lock")
+	require.NoError(t, err)
+	require.Empty(t, holder)
+}
+
+func TestLock_SingleConnection(t *testing.T) {
+	// the lock table does not need a dedicated connection, SQLite runs its statements
+	db := openSQLite(t)
+	for _, dialect := range []interface {
+		Dialect
+		ForceUnlocker
+	}{PostgresDialect{}, MySQLDialect{}} {
+		unlock, err := dialect.Lock(context.TODO(), db, "migration_schema", "pod-1", time.Second)
+		require.NoError(t, err)
+		_, err = dialect.Lock(context.TODO(), db, "migration_schema", "pod-2", 10*time.Millisecond)
+		require.ErrorIs(t, err, ErrLockTimeout)
+		require.ErrorContains(t, err, "held by pod-1 (since ")
+		require.NoError(t, unlock())
+
+		// the row of a crashed holder
+		_, err = dialect.Lock(context.TODO(), db, "migration_schema", "pod-1", time.Second)
+		require.NoError(t, err)
+		holder, err := dialect.ForceUnlock(context.TODO(), db, "migration_schema")
+		require.NoError(t, err)
+		require.Contains(t, holder, "pod-1 (since ")
+		unlock, err = dialect.Lock(context.TODO(), db, "migration_schema", "pod-2", time.Second)
+		require.NoError(t, err)
+		require.NoError(t, unlock())
+	}
+}
+
+func TestSQLiteDialect_LockDryRun(t *testing.T) {
+	db := openSQLite(t)
+	source := DirectoryMigrationSource{Directory: "./test_data/sqlite"}
+
+	// the status is computed by the constructor
+	_, err := NewUpMigrator(newSQLiteContext(db, source))
+	require.ErrorContains(t, err, "no such table: migration_schema")
+
+	migrator, err := NewCreateMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+
+	ctx := newSQLiteContext(db, source)
+	ctx.Conf.DryRun = true
+	migrator, err = NewUpMigrator(ctx)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.Empty(t, sqliteColumns(t, db, "migration_schema_lock"))
+	require.Empty(t, sqliteColumns(t, db, "users"))
+}
+
+func TestRepeatableMigration(t *testing.T) {
+	db := openSQLite(t)
+	view := &Migration{
+		Filename: "R__adults_view.sql",
+		Source: `
+-- +migrate Up
+DROP VIEW IF EXISTS adults;
+CREATE VIEW adults AS SELECT id, name FROM users WHERE age >= 18;
+`,
+	}
+	source := StringMigrationSource{Migrations: []*Migration{
+		view,
+		{
+			Filename: "V1__create_users.sql",
+			Source: `
+-- +migrate Up
+CREATE TABLE users (id INTEGER PRIMARY KEY NOT NULL, name TEXT NOT NULL, age INTEGER NOT NULL);
+INSERT INTO users (id, name, age) VALUES (1, 'alice', 30), (2, 'bob', 12);
+
+-- +migrate Down
+DROP TABLE users;
+`,
+		},
+	}}
+	status := func() []migrationStatus {
+		ctx := newSQLiteContext(db, source)
+		migrator, err := NewStatusMigrator(ctx)
+		require.NoError(t, err)
+		require.NoError(t, migrator.Apply())
+		return ctx.ms
+	}
+	countAdults := func() (count int) {
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM adults").Scan(&count))
+		return count
+	}
+
+	migrator, err := NewCreateMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+
+	ms := status()
+	require.Len(t, ms, 2)
+	require.Equal(t, "V1__create_users.sql", ms[0].migration.Filename)
+	require.Equal(t, StatusPending, ms[1].status)
+
+	// the repeatable migration runs after the versioned one it depends on
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.Equal(t, 1, countAdults())
+	ms = status()
+	require.Equal(t, StatusApplied, ms[0].status)
+	require.Equal(t, StatusApplied, ms[1].status)
+
+	// unchanged, it is not applied again
+	_, err = db.Exec("DROP VIEW adults")
+	require.NoError(t, err)
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.ErrorContains(t, db.QueryRow("SELECT COUNT(*) FROM adults").Scan(new(int)), "no such table")
+
+	// changed, it is applied again
+	view.Source = strings.ReplaceAll(view.Source, "age >= 18", "age >= 10")
+	require.Equal(t, StatusOutdated, status()[1].status)
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.Equal(t, 2, countAdults())
+	ms = status()
+	require.Equal(t, StatusApplied, ms[1].status)
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM migration_schema WHERE filename = ?", view.Filename).Scan(&count))
+	require.Equal(t, 1, count)
+
+	// repeatable migrations are not rolled back
+	migrator, err = NewDownMigrator(newSQLiteContext(db, source), "")
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.Equal(t, StatusPending, status()[0].status)
+	require.Equal(t, StatusApplied, status()[1].status)
+}
+
+func TestNoTransactionMigration(t *testing.T) {
+	db := openSQLite(t)
+	failing := &Migration{
+		Filename: "V2__failing.sql",
+		Source: `
+-- +migrate NoTransaction
+-- +migrate Up
+CREATE TABLE orders (id INTEGER PRIMARY KEY NOT NULL);
+INSERT INTO missing_table (id) VALUES (1);
+
+-- +migrate Down
+DROP TABLE orders;
+`,
+	}
+	source := StringMigrationSource{Migrations: []*Migration{
+		{
+			Filename: "V1__vacuum.sql",
+			Source: `
+-- +migrate Up
+-- +migrate NoTransaction
+CREATE TABLE users (id INTEGER PRIMARY KEY NOT NULL);
+VACUUM;
+
+-- +migrate Down
+DROP TABLE users;
+VACUUM;
+`,
+		},
+		failing,
+	}}
+	status := func() []migrationStatus {
+		ctx := newSQLiteContext(db, source)
+		migrator, err := NewStatusMigrator(ctx)
+		require.NoError(t, err)
+		require.NoError(t, migrator.Apply())
+		return ctx.ms
+	}
+
+	migrator, err := NewCreateMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+
+	// VACUUM, which cannot run in a transaction, is applied, the failing migration stops at its second statement
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	err = migrator.Apply()
+	require.ErrorContains(t, err, "statement 2 of 2")
+	require.ErrorContains(t, err, "partially applied")
+
+	// the statements before the failure are not rolled back
+	require.NotEmpty(t, sqliteColumns(t, db, "users"))
+	require.NotEmpty(t, sqliteColumns(t, db, "orders"))
+	ms := status()
+	require.Equal(t, StatusApplied, ms[0].status)
+	require.Equal(t, StatusPartiallyApplied, ms[1].status)
+
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.ErrorContains(t, migrator.Apply(), "V2__failing.sql, version 2, partially applied")
+	migrator, err = NewDownMigrator(newSQLiteContext(db, source), "")
+	require.NoError(t, err)
+	require.ErrorContains(t, migrator.Apply(), "partially applied")
+
+	// completed by hand
+	failing.Source = strings.ReplaceAll(failing.Source, "INSERT INTO missing_table (id) VALUES (1);\n", "")
+	_, err = db.Exec("DROP TABLE orders")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM migration_schema WHERE filename = ?", failing.Filename)
+	require.NoError(t, err)
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	for _, ms := range status() {
+		require.Equal(t, StatusApplied, ms.status)
+	}
+
+	migrator, err = NewDownMigrator(newSQLiteContext(db, source), "")
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.Empty(t, sqliteColumns(t, db, "users"))
+	for _, ms := range status() {
+		require.Equal(t, StatusPending, ms.status)
+	}
+}
+
+func TestNoTransactionRepeatableMigration(t *testing.T) {
+	db := openSQLite(t)
+	failing := &Migration{
+		Filename: "R__views.sql",
+		Source: `
+-- +migrate NoTransaction
+-- +migrate Up
+CREATE VIEW one AS SELECT 1 AS id;
+INSERT INTO missing_table (id) VALUES (1);
+`,
+	}
+	source := StringMigrationSource{Migrations: []*Migration{failing}}
+	status := func() Status {
+		ctx := newSQLiteContext(db, source)
+		migrator, err := NewStatusMigrator(ctx)
+		require.NoError(t, err)
+		require.NoError(t, migrator.Apply())
+		return ctx.ms[0].status
+	}
+
+	migrator, err := NewCreateMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.ErrorContains(t, migrator.Apply(), "statement 2 of 2")
+	require.Equal(t, StatusPartiallyApplied, status())
+
+	// an edit does not make it outdated, it is not run again over its applied statements
+	failing.Source = strings.ReplaceAll(failing.Source, "INSERT INTO missing_table (id) VALUES (1);\n", "")
+	require.Equal(t, StatusPartiallyApplied, status())
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.ErrorContains(t, migrator.Apply(), "R__views.sql, version , partially applied")
+}
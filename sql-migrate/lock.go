@@ -0,0 +1,406 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/hawkneo/utils/log"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultLockTimeout is the time to wait for the migration lock if Config.LockTimeout is not set
+	DefaultLockTimeout = time.Minute
+
+	// lockClassID is the first key of the Postgres advisory lock, the second one is the hash of the schema name
+	lockClassID = 0x6d696772 // "migr"
+	// lockPollInterval is the interval of the attempts to acquire a lock which cannot wait
+	lockPollInterval = 500 * time.Millisecond
+)
+
+// ErrLockTimeout is returned when the migration lock is held by another migrator for longer than the lock timeout
+var ErrLockTimeout = errors.New("migration lock timeout")
+
+// defaultLockHolder identifies this process in the migration lock, e.g. the pod name and pid
+func defaultLockHolder() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", hostname, os.Getpid())
+}
+
+// withLock runs fn while holding the migration lock, so concurrent migrators compute the status and
+// apply migrations one after another. A dry run writes nothing, so it runs fn without the lock, which
+// would create the lock table of SQLite.
+func withLock(ctx *Context, fn func() error) (err error) {
+	conf := ctx.Conf
+	if conf.DryRun {
+		return fn()
+	}
+	unlock, err := conf.Dialect.Lock(ctx.Context, conf.DB, conf.SchemaName, conf.LockHolder, conf.LockTimeout)
+	if err != nil {
+		return err
+	}
+	conf.Logger.Debugf("acquired migration lock of %s as %s", conf.SchemaName, conf.LockHolder)
+	defer func() {
+		if unlockErr := unlock(); unlockErr != nil {
+			if err == nil {
+				err = fmt.Errorf("release migration lock error: %w", unlockErr)
+			} else {
+				conf.Logger.Errorf("release migration lock error: %v", unlockErr)
+			}
+		}
+	}()
+	return fn()
+}
+
+// pollLock calls tryLock until it succeeds or timeout elapses
+func pollLock(ctx context.Context, timeout time.Duration, tryLock func() (bool, error)) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock()
+		if err != nil || locked {
+			return locked, err
+		}
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return false, nil
+		}
+		if wait > lockPollInterval {
+			wait = lockPollInterval
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func lockTimeoutError(schemaName, holder string, timeout time.Duration) error {
+	return fmt.Errorf("%w: lock of %s is held by %s, waited %s", ErrLockTimeout, schemaName, holder, timeout)
+}
+
+// singleConnection reports whether db is limited to one open connection. The migrations run on other
+// connections than the one holding an advisory lock, so they would wait for it forever, the lock table of
+// LockTable is used instead.
+func singleConnection(db *sql.DB) bool {
+	return db.Stats().MaxOpenConnections == 1
+}
+
+// Lock acquires a session level advisory lock on a dedicated connection. The application_name of the
+// connection is set to holder while the lock is held. If db is limited to one open connection, the lock is
+// the row of a lock table like the lock of SQLite.
+func (PostgresDialect) Lock(ctx context.Context, db *sql.DB, schemaName, holder string, timeout time.Duration) (func() error, error) {
+	if singleConnection(db) {
+		return lockTable(ctx, db, schemaName+"_lock", schemaName, holder, timeout, postgresBindVars)
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	locked, err := pollLock(ctx, timeout, func() (locked bool, err error) {
+		err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1, hashtext($2))", lockClassID, schemaName).Scan(&locked)
+		return locked, err
+	})
+	if err != nil || !locked {
+		defer conn.Close()
+		if err != nil {
+			return nil, fmt.Errorf("acquire migration lock error: %w", err)
+		}
+		var (
+			pid                            int64
+			applicationName, clientAddress string
+		)
+		err = conn.QueryRowContext(ctx, `
+SELECT a.pid, COALESCE(a.application_name, ''), COALESCE(HOST(a.client_addr), 'local')
+FROM pg_locks l JOIN pg_stat_activity a ON a.pid = l.pid
+WHERE l.locktype = 'advisory' AND l.granted AND l.classid = $1::INT4::OID AND l.objid = hashtext($2)::OID AND l.objsubid = 2
+`, lockClassID, schemaName).Scan(&pid, &applicationName, &clientAddress)
+		lockHolder := "unknown"
+		if err == nil {
+			lockHolder = fmt.Sprintf("%s (pid %d, client: %s)", applicationName, pid, clientAddress)
+		}
+		return nil, lockTimeoutError(schemaName, lockHolder, timeout)
+	}
+
+	var applicationName string
+	err = conn.QueryRowContext(ctx, "SELECT current_setting('application_name'), set_config('application_name', $1, false)", holder).
+		Scan(&applicationName, new(string))
+	if err != nil {
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1, hashtext($2))", lockClassID, schemaName)
+		conn.Close()
+		return nil, fmt.Errorf("set migration lock holder error: %w", err)
+	}
+	return func() error {
+		defer conn.Close()
+		_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1, hashtext($2)), set_config('application_name', $3, false)",
+			lockClassID, schemaName, applicationName,
+		)
+		return err
+	}, nil
+}
+
+// Lock acquires a named lock with GET_LOCK on a dedicated connection. holder is kept in the
+// @migrate_lock_holder variable of the connection, which other migrators read from performance_schema if
+// it is enabled. If db is limited to one open connection, the lock is the row of a lock table like the
+// lock of SQLite.
+func (MySQLDialect) Lock(ctx context.Context, db *sql.DB, schemaName, holder string, timeout time.Duration) (func() error, error) {
+	if singleConnection(db) {
+		return lockTable(ctx, db, schemaName+"_lock", schemaName, holder, timeout, nil)
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name := "migrate:" + schemaName
+	var locked sql.NullInt64
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, timeout.Seconds()).Scan(&locked)
+	if err != nil || locked.Int64 != 1 {
+		defer conn.Close()
+		if err != nil {
+			return nil, fmt.Errorf("acquire migration lock error: %w", err)
+		}
+		var (
+			connectionID int64
+			user, host   string
+		)
+		err = conn.QueryRowContext(ctx, `
+SELECT ID, USER, HOST FROM information_schema.PROCESSLIST WHERE ID = IS_USED_LOCK(?)
+`, name).Scan(&connectionID, &user, &host)
+		lockHolder := "unknown"
+		if err == nil {
+			lockHolder = fmt.Sprintf("connection %d (%s@%s)", connectionID, user, host)
+			var variable string
+			err = conn.QueryRowContext(ctx, `
+SELECT u.VARIABLE_VALUE FROM performance_schema.user_variables_by_thread u
+JOIN performance_schema.threads t ON t.THREAD_ID = u.THREAD_ID
+WHERE t.PROCESSLIST_ID = ? AND u.VARIABLE_NAME = 'migrate_lock_holder'
+`, connectionID).Scan(&variable)
+			if err == nil {
+				lockHolder = fmt.Sprintf("%s (connection %d, %s@%s)", variable, connectionID, user, host)
+			}
+		}
+		return nil, lockTimeoutError(schemaName, lockHolder, timeout)
+	}
+
+	_, err = conn.ExecContext(ctx, "SET @migrate_lock_holder = ?", holder)
+	if err != nil {
+		conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name)
+		conn.Close()
+		return nil, fmt.Errorf("set migration lock holder error: %w", err)
+	}
+	return func() error {
+		defer conn.Close()
+		if _, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name); err != nil {
+			return err
+		}
+		_, err := conn.ExecContext(context.Background(), "SET @migrate_lock_holder = NULL")
+		return err
+	}, nil
+}
+
+// ForceUnlock deletes the row of the lock table used if db is limited to one open connection, the advisory
+// lock is released with the session of its holder
+func (PostgresDialect) ForceUnlock(ctx context.Context, db *sql.DB, schemaName string) (string, error) {
+	if !singleConnection(db) {
+		return "", nil
+	}
+	return unlockTable(ctx, db, schemaName+"_lock", postgresBindVars)
+}
+
+// ForceUnlock deletes the row of the lock table used if db is limited to one open connection, the named
+// lock is released with the session of its holder
+func (MySQLDialect) ForceUnlock(ctx context.Context, db *sql.DB, schemaName string) (string, error) {
+	if !singleConnection(db) {
+		return "", nil
+	}
+	return unlockTable(ctx, db, schemaName+"_lock", nil)
+}
+
+// Lock inserts the row of a lock table, SQLite has no advisory locks
+func (SQLiteDialect) Lock(ctx context.Context, db *sql.DB, schemaName, holder string, timeout time.Duration) (func() error, error) {
+	return LockTable(ctx, db, schemaName+"_lock", schemaName, holder, timeout)
+}
+
+// ForceUnlock deletes the row of the lock table
+func (SQLiteDialect) ForceUnlock(ctx context.Context, db *sql.DB, schemaName string) (string, error) {
+	return UnlockTable(ctx, db, schemaName+"_lock")
+}
+
+// postgresBindVars replaces the ? placeholders of query with $1, $2...
+func postgresBindVars(query string) string {
+	var (
+		b strings.Builder
+		n int
+	)
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// createLockTable creates the lock table of LockTable if it does not exist
+func createLockTable(ctx context.Context, db *sql.DB, table string) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s
+(
+    id INTEGER PRIMARY KEY NOT NULL ,
+    holder TEXT NOT NULL ,
+    locked_at TIMESTAMP NOT NULL
+)
+`, table))
+	if err != nil {
+		return fmt.Errorf("create migration lock table error: %w", err)
+	}
+	return nil
+}
+
+// readLockHolder returns the holder of the row of the lock table and the time it was locked at,
+// sql.ErrNoRows if the lock is not held
+func readLockHolder(ctx context.Context, db *sql.DB, table string) (holder, lockedAt string, err error) {
+	var value interface{}
+	err = db.QueryRowContext(ctx, fmt.Sprintf("SELECT holder, locked_at FROM %s WHERE id = 1", table)).Scan(&holder, &value)
+	if err != nil {
+		return "", "", err
+	}
+	// a time.Time, or the text of drivers which do not parse timestamps
+	switch value := value.(type) {
+	case time.Time:
+		return holder, value.Format(time.RFC3339), nil
+	case []byte:
+		return holder, string(value), nil
+	}
+	return holder, fmt.Sprint(value), nil
+}
+
+// LockTable acquires the migration lock of schemaName by inserting the only row of table, which is created
+// if it does not exist. The row names its holder. It is the Lock of SQLite, of Postgres and MySQL if db is
+// limited to one open connection, and can be the Lock of the dialects of databases without advisory locks
+// whose driver supports ? placeholders.
+//
+// The row of a crashed holder is not released, UnlockTable deletes it.
+func LockTable(ctx context.Context, db *sql.DB, table, schemaName, holder string, timeout time.Duration) (func() error, error) {
+	return lockTable(ctx, db, table, schemaName, holder, timeout, nil)
+}
+
+// UnlockTable deletes the row of the lock table of LockTable whoever holds it, and returns its holder with
+// the time it was locked at. The holder is empty if the lock was not held.
+func UnlockTable(ctx context.Context, db *sql.DB, table string) (string, error) {
+	return unlockTable(ctx, db, table, nil)
+}
+
+// lockTable is LockTable, bindVars rewrites the ? placeholders of the statements if it is not nil
+func lockTable(ctx context.Context, db *sql.DB, table, schemaName, holder string, timeout time.Duration, bindVars func(string) string) (func() error, error) {
+	if bindVars == nil {
+		bindVars = func(query string) string { return query }
+	}
+	if err := createLockTable(ctx, db, table); err != nil {
+		return nil, err
+	}
+
+	locked, err := pollLock(ctx, timeout, func() (bool, error) {
+		res, err := db.ExecContext(ctx, bindVars(fmt.Sprintf(`
+INSERT INTO %s (id, holder, locked_at) SELECT 1, ?, CURRENT_TIMESTAMP FROM (SELECT COUNT(*) AS n FROM %s) c WHERE c.n = 0
+`, table, table)), holder)
+		if err != nil {
+			// another holder inserted the row first
+			var n int
+			if countErr := db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", table)).Scan(&n); countErr == nil && n > 0 {
+				return false, nil
+			}
+			return false, err
+		}
+		rows, err := res.RowsAffected()
+		return rows == 1, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("acquire migration lock error: %w", err)
+	}
+	if !locked {
+		lockHolder := "unknown"
+		if holder, lockedAt, err := readLockHolder(ctx, db, table); err == nil {
+			lockHolder = fmt.Sprintf("%s (since %s)", holder, lockedAt)
+		}
+		return nil, lockTimeoutError(schemaName, lockHolder, timeout)
+	}
+	return func() error {
+		_, err := db.ExecContext(context.Background(), bindVars(fmt.Sprintf("DELETE FROM %s WHERE id = 1 AND holder = ?", table)), holder)
+		return err
+	}, nil
+}
+
+// unlockTable is UnlockTable, bindVars rewrites the ? placeholders of the statements if it is not nil
+func unlockTable(ctx context.Context, db *sql.DB, table string, bindVars func(string) string) (string, error) {
+	if bindVars == nil {
+		bindVars = func(query string) string { return query }
+	}
+	if err := createLockTable(ctx, db, table); err != nil {
+		return "", err
+	}
+	holder, lockedAt, err := readLockHolder(ctx, db, table)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read migration lock error: %w", err)
+	}
+	// not the row of a holder which acquired the lock in the meantime
+	_, err = db.ExecContext(ctx, bindVars(fmt.Sprintf("DELETE FROM %s WHERE id = 1 AND holder = ?", table)), holder)
+	if err != nil {
+		return "", fmt.Errorf("release migration lock error: %w", err)
+	}
+	return fmt.Sprintf("%s (since %s)", holder, lockedAt), nil
+}
+
+var _ Migrator = (*unlockMigrator)(nil)
+
+type unlockMigrator struct {
+	ctx *Context
+}
+
+// NewUnlockMigrator creates a migrator which releases the migration lock left by a crashed holder, the row
+// of a lock table. Only run it when the holder is known to be dead, or two migrators would run at the same
+// time. The advisory locks of Postgres and MySQL are released with the session of their holder, there is
+// only a row to release if db is limited to one open connection.
+func NewUnlockMigrator(ctx *Context) (Migrator, error) {
+	err := loadContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return unlockMigrator{ctx}, nil
+}
+
+func (migrator unlockMigrator) Apply() error {
+	conf := migrator.ctx.Conf
+	unlocker, ok := conf.Dialect.(ForceUnlocker)
+	if !ok {
+		conf.Logger.Infof("the migration lock of %s is released with the session of its holder, nothing to release", conf.SchemaName)
+		return nil
+	}
+	if conf.DryRun {
+		conf.Logger.Infof("dry run, the migration lock of %s is not released", conf.SchemaName)
+		return nil
+	}
+	holder, err := unlocker.ForceUnlock(migrator.ctx.Context, conf.DB, conf.SchemaName)
+	if err != nil {
+		return err
+	}
+	if holder == "" {
+		conf.Logger.Infof("no migration lock of %s to release", conf.SchemaName)
+		return nil
+	}
+	conf.Logger.Infof("%s", log.AnsiColorGreen(fmt.Sprintf("released the migration lock of %s held by %s", conf.SchemaName, holder)))
+	return nil
+}
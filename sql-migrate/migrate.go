@@ -32,8 +32,19 @@ const (
 
 	StatusHashMismatch     Status = "hashMismatch"
 	StatusFilenameMismatch Status = "filenameMismatch"
+
+	// StatusOutdated is the status of a repeatable migration whose content changed since it was applied
+	StatusOutdated Status = "outdated"
+	// StatusPartiallyApplied is the status of a NoTransaction migration whose statements failed midway,
+	// it must be completed or reverted by hand
+	StatusPartiallyApplied Status = "partiallyApplied"
 )
 
+// repeatableVersion is the version repeatable migrations are recorded with. They have no version, and
+// are told apart by their R__ filename, but the version column of the MySQL schema tables created by older
+// versions is a BIGINT, which does not accept an empty version.
+const repeatableVersion = "0"
+
 func (status Status) AnsiColorString() string {
 	switch status {
 	case StatusBaseline:
@@ -42,12 +53,16 @@ func (status Status) AnsiColorString() string {
 		return log.AnsiColorGreen(status).AnsiColorString()
 	case StatusPending:
 		return log.AnsiColorBlue(status).AnsiColorString()
+	case StatusOutdated:
+		return log.AnsiColorBlue(status).AnsiColorString()
 	case StatusOutOfOrder:
 		return log.AnsiColorYellow(status).AnsiColorString()
 	case StatusHashMismatch:
 		return log.AnsiColorRed(status).AnsiColorString()
 	case StatusFilenameMismatch:
 		return log.AnsiColorRed(status).AnsiColorString()
+	case StatusPartiallyApplied:
+		return log.AnsiColorRed(status).AnsiColorString()
 	default:
 		return log.AnsiColorRed(status).AnsiColorString()
 	}
@@ -58,9 +73,19 @@ type Migration struct {
 	Source   string
 
 	version        string
+	repeatable     bool
+	noTransaction  bool
 	fileHash       string
 	upStatements   []Statement
 	downStatements []Statement
+	// upFunc and downFunc are set instead of the statements for a GoMigration
+	upFunc   func(ctx context.Context, tx *sql.Tx) error
+	downFunc func(ctx context.Context, tx *sql.Tx) error
+}
+
+// isGo reports whether the migration is a GoMigration
+func (migration *Migration) isGo() bool {
+	return migration.upFunc != nil
 }
 
 type Schema struct {
@@ -84,15 +109,56 @@ type Context struct {
 
 	migrations []*Migration
 	ms         []migrationStatus
+	// schemas are all the rows of the schema table, ordered by id
+	schemas []*Schema
 }
 
 func fillContext(ctx *Context, parseUpStatement, parseDownStatement bool) error {
+	if err := loadContext(ctx); err != nil {
+		return err
+	}
+
+	mysql := isMySQL(ctx.Conf.Dialect)
+	for _, migration := range ctx.migrations {
+		if migration.isGo() {
+			continue
+		}
+		var err error
+		if parseUpStatement {
+			migration.upStatements, migration.noTransaction, err = splitSQLStatements(
+				migration.Filename, strings.NewReader(migration.Source), true, mysql,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		if parseDownStatement {
+			migration.downStatements, migration.noTransaction, err = splitSQLStatements(
+				migration.Filename, strings.NewReader(migration.Source), false, mysql,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return validateVersions(ctx.migrations)
+}
+
+// loadContext sets the defaults of the config and loads the sorted migrations without parsing them
+func loadContext(ctx *Context) error {
 	if ctx.Conf.Logger == nil {
 		ctx.Conf.Logger = &log.ConsoleLogger{Level: log.LevelInfo}
 	}
 	if ctx.Conf.SchemaName == "" {
 		ctx.Conf.SchemaName = "migration_schema"
 	}
+	if ctx.Conf.LockTimeout == 0 {
+		ctx.Conf.LockTimeout = DefaultLockTimeout
+	}
+	if ctx.Conf.LockHolder == "" {
+		ctx.Conf.LockHolder = defaultLockHolder()
+	}
 	if ctx.Conf.MigrationSource == nil {
 		return fmt.Errorf("migration source is not set")
 	}
@@ -104,32 +170,62 @@ func fillContext(ctx *Context, parseUpStatement, parseDownStatement bool) error
 
 	for _, migration := range migrations {
 		migration.version = SplitFilename(migration.Filename)
+		migration.repeatable = IsRepeatableFilename(migration.Filename)
 		migration.fileHash = fmt.Sprintf("%x", md5.Sum([]byte(migration.Source)))
-
-		if parseUpStatement {
-			migration.upStatements = splitSQLStatements(strings.NewReader(migration.Source), true)
-		}
-		if parseDownStatement {
-			migration.downStatements = splitSQLStatements(strings.NewReader(migration.Source), false)
-		}
 	}
+	// repeatable migrations are applied after the versioned ones, by filename
 	sort.Slice(migrations, func(i, j int) bool {
+		if migrations[i].repeatable != migrations[j].repeatable {
+			return !migrations[i].repeatable
+		}
+		if migrations[i].repeatable {
+			return migrations[i].Filename < migrations[j].Filename
+		}
 		vi := migrations[i].version
 		vj := migrations[j].version
 		return CompareVersion(vi, vj)
 	})
 
-	// check duplicate version
-	for i := 1; i < len(migrations); i++ {
-		if migrations[i-1].version == migrations[i].version {
-			return fmt.Errorf("duplicate version: %s", migrations[i].version)
-		}
-	}
-
 	ctx.migrations = migrations
 	return nil
 }
 
+// validateVersions returns an error if a versioned migration has an invalid version, or if two of them have
+// the same version or ambiguous versions, e.g. 1.0 and 1
+func validateVersions(migrations []*Migration) error {
+	if errs := versionErrors(migrations); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// versionErrors returns all the errors validateVersions returns the first of
+func versionErrors(migrations []*Migration) (errs []error) {
+	normalizedToMigration := make(map[string]*Migration)
+	for _, migration := range migrations {
+		if migration.repeatable {
+			continue
+		}
+		if err := ValidateVersion(migration.version); err != nil {
+			errs = append(errs, fmt.Errorf("filename: %s, %w", migration.Filename, err))
+			continue
+		}
+		normalized := normalizeVersion(migration.version)
+		if other, ok := normalizedToMigration[normalized]; ok {
+			if other.version == migration.version {
+				errs = append(errs, fmt.Errorf("duplicate version: %s", migration.version))
+			} else {
+				errs = append(errs, fmt.Errorf("ambiguous versions: %s (%s) and %s (%s)",
+					other.version, other.Filename, migration.version, migration.Filename,
+				))
+			}
+			continue
+		}
+		normalizedToMigration[normalized] = migration
+	}
+	return errs
+}
+
 type Migrator interface {
 	Apply() error
 }
@@ -173,16 +269,24 @@ func NewBaselineMigrator(ctx *Context) (Migrator, error) {
 	if err != nil {
 		return nil, err
 	}
-	ctx.Conf.printStatus = false
-	statusMigrator := &statusMigrator{ctx}
-	err = statusMigrator.Apply()
+	err = computeStatus(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return baselineMigrator{ctx}, err
+	return baselineMigrator{ctx}, nil
 }
 
+// Apply baselines the database while holding the migration lock
 func (migrator baselineMigrator) Apply() error {
+	return withLock(migrator.ctx, func() error {
+		if err := computeStatus(migrator.ctx); err != nil {
+			return err
+		}
+		return migrator.apply()
+	})
+}
+
+func (migrator baselineMigrator) apply() error {
 	ctx := migrator.ctx
 	for _, ms := range ctx.ms {
 		if ms.status != StatusPending {
@@ -206,6 +310,10 @@ func (migrator baselineMigrator) Apply() error {
 	}()
 
 	for _, ms := range ctx.ms {
+		// repeatable migrations are applied by the next migration up
+		if ms.migration.repeatable {
+			continue
+		}
 		ctx.Conf.schemaMaxID++
 		schema := &Schema{
 			ID:        ctx.Conf.schemaMaxID,
@@ -241,13 +349,21 @@ func NewUpMigrator(ctx *Context) (Migrator, error) {
 	if err != nil {
 		return nil, err
 	}
-	ctx.Conf.printStatus = false
-	statusMigrator := &statusMigrator{ctx}
-	err = statusMigrator.Apply()
+	err = computeStatus(ctx)
 	return upMigrator{ctx}, err
 }
 
+// Apply applies the pending migrations while holding the migration lock
 func (migrator upMigrator) Apply() error {
+	return withLock(migrator.ctx, func() error {
+		if err := computeStatus(migrator.ctx); err != nil {
+			return err
+		}
+		return migrator.apply()
+	})
+}
+
+func (migrator upMigrator) apply() error {
 	conf := migrator.ctx.Conf
 
 	for _, ms := range migrator.ctx.ms {
@@ -260,6 +376,11 @@ func (migrator upMigrator) Apply() error {
 			return fmt.Errorf("filename: %s, version %s, filename mismatch, expected: %s, actual: %s",
 				ms.migration.Filename, ms.migration.version, ms.schema.Filename, ms.migration.Filename,
 			)
+		case StatusPartiallyApplied:
+			return fmt.Errorf("filename: %s, version %s, partially applied, complete or revert its statements "+
+				"by hand, then delete its row from %s",
+				ms.migration.Filename, ms.migration.version, migrator.ctx.Conf.SchemaName,
+			)
 		}
 	}
 
@@ -300,13 +421,21 @@ func NewDownMigrator(ctx *Context, toVersion string) (Migrator, error) {
 	if err != nil {
 		return nil, err
 	}
-	ctx.Conf.printStatus = false
-	statusMigrator := &statusMigrator{ctx}
-	err = statusMigrator.Apply()
+	err = computeStatus(ctx)
 	return downMigrator{ctx, toVersion}, err
 }
 
+// Apply rolls back the applied migrations while holding the migration lock
 func (migrator downMigrator) Apply() error {
+	return withLock(migrator.ctx, func() error {
+		if err := computeStatus(migrator.ctx); err != nil {
+			return err
+		}
+		return migrator.apply()
+	})
+}
+
+func (migrator downMigrator) apply() error {
 	targetVersionExists := migrator.toVersion == ""
 	for _, ms := range migrator.ctx.ms {
 		if !targetVersionExists {
@@ -321,6 +450,11 @@ func (migrator downMigrator) Apply() error {
 			return fmt.Errorf("filename: %s, version %s, filename mismatch, expected: %s, actual: %s",
 				ms.migration.Filename, ms.migration.version, ms.schema.Filename, ms.migration.Filename,
 			)
+		case StatusPartiallyApplied:
+			return fmt.Errorf("filename: %s, version %s, partially applied, complete or revert its statements "+
+				"by hand, then delete its row from %s",
+				ms.migration.Filename, ms.migration.version, migrator.ctx.Conf.SchemaName,
+			)
 		}
 	}
 	if !targetVersionExists {
@@ -330,7 +464,8 @@ func (migrator downMigrator) Apply() error {
 	// apply migrations
 	for i := len(migrator.ctx.ms) - 1; i >= 0; i-- {
 		ms := migrator.ctx.ms[i]
-		if ms.status != StatusApplied {
+		// repeatable migrations are not rolled back
+		if ms.status != StatusApplied || ms.migration.repeatable {
 			continue
 		}
 		if migrator.toVersion != "" && !CompareVersion(migrator.toVersion, ms.migration.version) {
@@ -353,9 +488,14 @@ func (migrator downMigrator) Apply() error {
 }
 
 func apply(ctx *Context, migration *Migration, up bool) (schema *Schema, err error) {
+	if migration.noTransaction {
+		return applyWithoutTransaction(ctx, migration, up)
+	}
 	statements := migration.upStatements
+	goFunc := migration.upFunc
 	if !up {
 		statements = migration.downStatements
+		goFunc = migration.downFunc
 	}
 	db := ctx.Conf.DB
 	tx, err := db.BeginTx(ctx.Context, &sql.TxOptions{})
@@ -392,6 +532,15 @@ func apply(ctx *Context, migration *Migration, up bool) (schema *Schema, err err
 			migration.Filename, migration.version, statement,
 		)
 	}
+	if goFunc != nil {
+		if ctx.Conf.DryRun {
+			fmt.Printf("-- go migration %s\n", migration.Filename)
+		} else if err := goFunc(ctx.Context, tx); err != nil {
+			panic(fmt.Errorf("apply filename: %s, version: %s, go migration error: %w",
+				migration.Filename, migration.version, err,
+			))
+		}
+	}
 	ctx.Conf.Logger.Infof("apply filename: %s, version: %s success", migration.Filename, migration.version)
 
 	if ctx.Conf.DryRun {
@@ -399,10 +548,23 @@ func apply(ctx *Context, migration *Migration, up bool) (schema *Schema, err err
 	}
 
 	if up {
+		version := migration.version
+		if migration.repeatable {
+			version = repeatableVersion
+			// replace the record of the previous content
+			_, err = tx.ExecContext(
+				ctx.Context,
+				ctx.Conf.Dialect.DeleteSchemaSQL(ctx.Conf.SchemaName),
+				migration.Filename,
+			)
+			if err != nil {
+				panic(fmt.Errorf("apply filename: %s, delete schema error: %s", migration.Filename, err))
+			}
+		}
 		ctx.Conf.schemaMaxID++
 		schema = &Schema{
 			ID:        ctx.Conf.schemaMaxID,
-			Version:   migration.version,
+			Version:   version,
 			Filename:  migration.Filename,
 			Hash:      migration.fileHash,
 			Status:    StatusApplied,
@@ -434,10 +596,114 @@ func apply(ctx *Context, migration *Migration, up bool) (schema *Schema, err err
 	return nil, nil
 }
 
+// applyWithoutTransaction runs the statements of a NoTransaction migration one by one.
+// The migration is recorded as StatusPartiallyApplied until all its statements succeed,
+// so a migration which fails midway is detected by the next run.
+func applyWithoutTransaction(ctx *Context, migration *Migration, up bool) (*Schema, error) {
+	statements := migration.upStatements
+	if !up {
+		statements = migration.downStatements
+	}
+	if ctx.Conf.DryRun {
+		for _, statement := range statements {
+			fmt.Println(string(statement))
+		}
+		ctx.Conf.Logger.Infof("apply filename: %s, version: %s success", migration.Filename, migration.version)
+		return nil, nil
+	}
+
+	version := migration.version
+	if migration.repeatable {
+		version = repeatableVersion
+	}
+	ctx.Conf.schemaMaxID++
+	schema := &Schema{
+		ID:        ctx.Conf.schemaMaxID,
+		Version:   version,
+		Filename:  migration.Filename,
+		Hash:      migration.fileHash,
+		Status:    StatusPartiallyApplied,
+		CreatedAt: time.Now(),
+	}
+	if err := replaceSchema(ctx, migration, schema); err != nil {
+		return nil, err
+	}
+
+	for i, statement := range statements {
+		_, err := ctx.Conf.DB.ExecContext(ctx.Context, string(statement))
+		if err != nil {
+			return nil, fmt.Errorf("apply filename: %s, version: %s, statement %d of %d: %s, error: %s, "+
+				"the migration is partially applied",
+				migration.Filename, migration.version, i+1, len(statements), statement, err,
+			)
+		}
+		ctx.Conf.Logger.Debugf("apply filename: %s, version: %s, statement %d of %d: %s success",
+			migration.Filename, migration.version, i+1, len(statements), statement,
+		)
+	}
+	ctx.Conf.Logger.Infof("apply filename: %s, version: %s success", migration.Filename, migration.version)
+
+	if !up {
+		return nil, replaceSchema(ctx, migration, nil)
+	}
+	schema.Status = StatusApplied
+	if err := replaceSchema(ctx, migration, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// replaceSchema replaces the record of migration with schema, or deletes it if schema is nil
+func replaceSchema(ctx *Context, migration *Migration, schema *Schema) (err error) {
+	tx, err := ctx.Conf.DB.BeginTx(ctx.Context, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx.Context, ctx.Conf.Dialect.DeleteSchemaSQL(ctx.Conf.SchemaName), migration.Filename)
+	if err != nil {
+		return fmt.Errorf("apply filename: %s, version: %s, delete schema error: %s",
+			migration.Filename, migration.version, err,
+		)
+	}
+	if schema == nil {
+		return nil
+	}
+	_, err = tx.ExecContext(
+		ctx.Context,
+		ctx.Conf.Dialect.InsertSchemaSQL(ctx.Conf.SchemaName),
+		schema.ID, schema.Version, schema.Filename, schema.Hash, schema.Status, schema.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("apply filename: %s, version: %s, insert schema error: %s",
+			migration.Filename, migration.version, err,
+		)
+	}
+	return nil
+}
+
 type statusMigrator struct {
 	ctx *Context
 }
 
+// computeStatus fills the status of the migrations without printing it. The migrators compute it in their
+// constructor to report errors early, and again in Apply while holding the migration lock.
+func computeStatus(ctx *Context) error {
+	printStatus := ctx.Conf.printStatus
+	ctx.Conf.printStatus = false
+	defer func() {
+		ctx.Conf.printStatus = printStatus
+	}()
+	return statusMigrator{ctx}.Apply()
+}
+
 func NewStatusMigrator(ctx *Context) (Migrator, error) {
 	ctx.Conf.printStatus = true
 	err := fillContext(ctx, false, false)
@@ -456,18 +722,24 @@ SELECT id, version, filename, hash, status, created_at FROM %s ORDER BY id ASC
 	}
 
 	// read schemas from db
+	allSchemas := make([]*Schema, 0)
 	schemas := make([]*Schema, 0)
 	versionToSchema := make(map[string]*Schema, 0)
+	filenameToRepeatableSchema := make(map[string]*Schema, 0)
 	for rows.Next() {
 		schema := &Schema{}
 		err := rows.Scan(&schema.ID, &schema.Version, &schema.Filename, &schema.Hash, &schema.Status, &schema.CreatedAt)
 		if err != nil {
 			return err
 		}
+		migrator.ctx.Conf.schemaMaxID = schema.ID
+		allSchemas = append(allSchemas, schema)
+		if IsRepeatableFilename(schema.Filename) {
+			filenameToRepeatableSchema[schema.Filename] = schema
+			continue
+		}
 		schemas = append(schemas, schema)
 		versionToSchema[schema.Version] = schema
-
-		migrator.ctx.Conf.schemaMaxID = schema.ID
 	}
 	rows.Close()
 	sort.Slice(schemas, func(i, j int) bool {
@@ -484,8 +756,21 @@ SELECT id, version, filename, hash, status, created_at FROM %s ORDER BY id ASC
 		statusMaxLength   = len("Status")
 	)
 	for _, migration := range migrator.ctx.migrations {
-		schema, ok := versionToSchema[migration.version]
-		if !ok {
+		if migration.repeatable {
+			schema, ok := filenameToRepeatableSchema[migration.Filename]
+			status := StatusPending
+			// an edited migration which failed midway must be completed by hand before it runs again
+			if ok && schema.Status != StatusPartiallyApplied && schema.Hash != migration.fileHash {
+				status = StatusOutdated
+			} else if ok {
+				status = schema.Status
+			}
+			ms = append(ms, migrationStatus{
+				migration: migration,
+				schema:    schema,
+				status:    status,
+			})
+		} else if schema, ok := versionToSchema[migration.version]; !ok {
 			status := StatusPending
 			if len(schemas) > 0 && CompareVersion(migration.version, schemas[len(schemas)-1].Version) {
 				status = StatusOutOfOrder
@@ -524,6 +809,7 @@ SELECT id, version, filename, hash, status, created_at FROM %s ORDER BY id ASC
 		}
 	}
 	migrator.ctx.ms = ms
+	migrator.ctx.schemas = allSchemas
 
 	// print status
 	if !migrator.ctx.Conf.printStatus {
@@ -2,123 +2,344 @@ package migrate
 
 import (
 	"bufio"
-	"bytes"
+	"errors"
+	"fmt"
 	"io"
-	"log"
+	"regexp"
 	"strings"
 )
 
 const sqlCmdPrefix = "-- +migrate "
 
-// Checks the line to see if the line has a statement-ending semicolon
-// or if the line contains a double-dash comment.
-func endsWithSemicolon(line string) bool {
+var (
+	dollarTagRegex = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+	delimiterRegex = regexp.MustCompile(`(?i)^\s*DELIMITER\s+(\S+)\s*$`)
+)
 
-	prev := ""
-	scanner := bufio.NewScanner(strings.NewReader(line))
-	scanner.Split(bufio.ScanWords)
+// ParseError is the error of a migration file which cannot be split into statements
+type ParseError struct {
+	Filename string
+	Line     int
+	Message  string
+}
 
-	for scanner.Scan() {
-		word := scanner.Text()
-		if strings.HasPrefix(word, "--") {
-			break
-		}
-		prev = word
-	}
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.Filename, e.Line, e.Message)
+}
+
+// sqlLexer splits a migration file into the statements of its sections.
+//
+// Delimiters inside string literals, quoted identifiers, comments and Postgres dollar-quoted strings
+// do not end a statement. A 'DELIMITER' line changes the delimiter like the MySQL client does, until
+// the next one or the next section.
+type sqlLexer struct {
+	filename string
+	// mysql enables backslash escapes in strings, '#' comments and disables dollar-quoted strings
+	mysql bool
+
+	line      int
+	delimiter string
+	section   string // "Up", "Down", or empty before the first section
 
-	return strings.HasSuffix(prev, ";")
+	// quote is the quote of the string or quoted identifier being lexed
+	quote            byte
+	backslashEscapes bool
+	// commentDepth is the depth of the nested block comments being lexed
+	commentDepth int
+	// dollarTag is the tag of the dollar-quoted string being lexed, e.g. "$body$"
+	dollarTag string
+	// tokenLine is the line where the string, comment or StatementBegin block being lexed started
+	tokenLine int
+
+	// statementBlock is set between StatementBegin and StatementEnd
+	statementBlock bool
+
+	buf        strings.Builder
+	hasContent bool // buf has more than whitespace and comments
+	bufLine    int  // line of the first content of buf
+
+	sections      map[string]bool // sections found in the script
+	statements    map[string][]Statement
+	noTransaction bool
+	// outsideLine is the line of the first statement outside of a section
+	outsideLine int
 }
 
-// Split the given sql script into individual statements.
+// splitSQLStatements splits the given sql script into the individual statements of a direction.
 //
-// The base case is to simply split on semicolons, as these
-// naturally terminate a statement.
+// The statements are delimited by semicolons, or by the delimiter set with a 'DELIMITER' line.
+// Semicolons within string literals, comments and dollar-quoted bodies of pl/pgsql functions
+// are recognized, and do not end a statement.
 //
-// However, more complex cases like pl/pgsql can have semicolons
-// within a statement. For these cases, we provide the explicit annotations
-// 'StatementBegin' and 'StatementEnd' to allow the script to
-// tell us to ignore semicolons.
-func splitSQLStatements(r io.Reader, direction bool) (stmts []Statement) {
-
-	var buf bytes.Buffer
-	scanner := bufio.NewScanner(r)
-
-	// track the count of each section
-	// so we can diagnose scripts with no annotations
-	upSections := 0
-	downSections := 0
-
-	statementEnded := false
-	ignoreSemicolons := false
-	directionIsActive := false
-
-	for scanner.Scan() {
-
-		line := scanner.Text()
-
-		// handle any goose-specific commands
-		if strings.HasPrefix(line, sqlCmdPrefix) {
-			cmd := strings.TrimSpace(line[len(sqlCmdPrefix):])
-			switch cmd {
-			case "Up":
-				directionIsActive = direction == true
-				upSections++
-				break
-
-			case "Down":
-				directionIsActive = direction == false
-				downSections++
-				break
-
-			case "StatementBegin":
-				if directionIsActive {
-					ignoreSemicolons = true
-				}
-				break
+// For other cases, we provide the explicit annotations 'StatementBegin' and 'StatementEnd'
+// to tell us to take the enclosed lines as one statement.
+//
+// The annotation 'NoTransaction' anywhere in the script tells us to run
+// the statements of both directions outside a transaction.
+//
+// The returned error is a *ParseError with the filename and line of the problem.
+func splitSQLStatements(filename string, r io.Reader, direction bool, mysql bool) (stmts []Statement, noTransaction bool, err error) {
+	lexer, err := lexSQL(filename, r, mysql)
+	if err != nil {
+		return nil, false, err
+	}
+	if direction {
+		return lexer.statements["Up"], lexer.noTransaction, nil
+	}
+	return lexer.statements["Down"], lexer.noTransaction, nil
+}
 
-			case "StatementEnd":
-				if directionIsActive {
-					statementEnded = (ignoreSemicolons == true)
-					ignoreSemicolons = false
-				}
-				break
+// lexSQL lexes the sql script of filename
+func lexSQL(filename string, r io.Reader, mysql bool) (*sqlLexer, error) {
+	lexer := &sqlLexer{
+		filename:   filename,
+		mysql:      mysql,
+		delimiter:  ";",
+		sections:   make(map[string]bool),
+		statements: make(map[string][]Statement),
+	}
+	if err := lexer.lex(r); err != nil {
+		return nil, err
+	}
+	return lexer, nil
+}
+
+func (lexer *sqlLexer) errorf(line int, format string, args ...interface{}) error {
+	return &ParseError{Filename: lexer.filename, Line: line, Message: fmt.Sprintf(format, args...)}
+}
+
+func (lexer *sqlLexer) lex(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return lexer.errorf(lexer.line, "read error: %v", err)
+		}
+		if len(line) == 0 && err != nil {
+			break
+		}
+		lexer.line++
+
+		lexing := lexer.quote == 0 && lexer.commentDepth == 0 && lexer.dollarTag == ""
+		if trimmed := strings.TrimSpace(line); lexing && strings.HasPrefix(trimmed, sqlCmdPrefix) {
+			cmd := strings.TrimSpace(trimmed[len(sqlCmdPrefix):])
+			if err := lexer.annotation(cmd); err != nil {
+				return err
 			}
+		} else if lexer.statementBlock {
+			lexer.write(line)
+		} else if match := delimiterRegex.FindStringSubmatch(line); lexing && match != nil && !lexer.hasContent {
+			lexer.delimiter = match[1]
+			lexer.buf.Reset()
+		} else {
+			lexer.lexLine(line)
+		}
+
+		if err != nil {
+			break
 		}
+	}
+
+	switch {
+	case lexer.quote != 0:
+		return lexer.errorf(lexer.tokenLine, "unterminated quoted string or identifier %c", lexer.quote)
+	case lexer.commentDepth > 0:
+		return lexer.errorf(lexer.tokenLine, "unterminated block comment")
+	case lexer.dollarTag != "":
+		return lexer.errorf(lexer.tokenLine, "unterminated dollar-quoted string %s", lexer.dollarTag)
+	case lexer.statementBlock:
+		return lexer.errorf(lexer.tokenLine, "saw '-- +migrate StatementBegin' with no matching '-- +migrate StatementEnd'")
+	}
+	if len(lexer.sections) == 0 {
+		return lexer.errorf(1, "no Up/Down annotations found")
+	}
+	if err := lexer.checkTerminated(); err != nil {
+		return err
+	}
+	if lexer.outsideLine > 0 {
+		return lexer.errorf(lexer.outsideLine, "statement outside of an Up/Down section")
+	}
+	return nil
+}
 
-		if !directionIsActive {
-			continue
+func (lexer *sqlLexer) annotation(cmd string) error {
+	switch cmd {
+	case "Up", "Down":
+		if err := lexer.checkTerminated(); err != nil {
+			return err
 		}
+		lexer.section = cmd
+		lexer.sections[cmd] = true
+		lexer.delimiter = ";"
+		lexer.buf.Reset()
 
-		if _, err := buf.WriteString(line + "\n"); err != nil {
-			log.Fatalf("io err: %v", err)
+	case "StatementBegin":
+		if lexer.statementBlock {
+			return lexer.errorf(lexer.line, "nested '-- +migrate StatementBegin'")
+		}
+		if err := lexer.checkTerminated(); err != nil {
+			return err
 		}
+		lexer.statementBlock = true
+		lexer.tokenLine = lexer.line
+		lexer.buf.Reset()
 
-		// Wrap up the two supported cases: 1) basic with semicolon; 2) psql statement
-		// Lines that end with semicolon that are in a statement block
-		// do not conclude statement.
-		if (!ignoreSemicolons && endsWithSemicolon(line)) || statementEnded {
-			statementEnded = false
-			stmts = append(stmts, Statement(buf.String()))
-			buf.Reset()
+	case "StatementEnd":
+		if !lexer.statementBlock {
+			return lexer.errorf(lexer.line, "saw '-- +migrate StatementEnd' with no matching '-- +migrate StatementBegin'")
 		}
+		lexer.statementBlock = false
+		lexer.hasContent = strings.TrimSpace(lexer.buf.String()) != ""
+		lexer.bufLine = lexer.tokenLine
+		lexer.emit("")
+
+	case "NoTransaction":
+		lexer.noTransaction = true
+
+	default:
+		return lexer.errorf(lexer.line, "unknown annotation %q", cmd)
 	}
+	return nil
+}
 
-	if err := scanner.Err(); err != nil {
-		log.Fatalf("scanning migration: %v", err)
+// checkTerminated returns an error if the buffered statement is not terminated
+func (lexer *sqlLexer) checkTerminated() error {
+	if !lexer.hasContent {
+		return nil
+	}
+	if lexer.section == "" {
+		return lexer.errorf(lexer.bufLine, "statement outside of an Up/Down section")
 	}
+	return lexer.errorf(lexer.bufLine, "statement is not terminated by %q", lexer.delimiter)
+}
+
+func (lexer *sqlLexer) lexLine(line string) {
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case lexer.quote != 0:
+			if lexer.backslashEscapes && c == '\\' && i+1 < len(line) {
+				lexer.write(line[i : i+2])
+				i += 2
+				continue
+			}
+			if c == lexer.quote {
+				if i+1 < len(line) && line[i+1] == lexer.quote {
+					// doubled quote
+					lexer.write(line[i : i+2])
+					i += 2
+					continue
+				}
+				lexer.quote = 0
+			}
+			lexer.write(line[i : i+1])
+			i++
+
+		case lexer.commentDepth > 0:
+			if strings.HasPrefix(line[i:], "*/") {
+				lexer.commentDepth--
+				lexer.write("*/")
+				i += 2
+			} else if !lexer.mysql && strings.HasPrefix(line[i:], "/*") {
+				lexer.commentDepth++
+				lexer.write("/*")
+				i += 2
+			} else {
+				lexer.write(line[i : i+1])
+				i++
+			}
+
+		case lexer.dollarTag != "":
+			if strings.HasPrefix(line[i:], lexer.dollarTag) {
+				lexer.write(lexer.dollarTag)
+				i += len(lexer.dollarTag)
+				lexer.dollarTag = ""
+			} else {
+				lexer.write(line[i : i+1])
+				i++
+			}
 
-	// diagnose likely migration script errors
-	if ignoreSemicolons {
-		log.Println("WARNING: saw '-- +migrate StatementBegin' with no matching '-- +migrate StatementEnd'")
+		case strings.HasPrefix(line[i:], lexer.delimiter):
+			lexer.emit(lexer.delimiter)
+			i += len(lexer.delimiter)
+
+		case strings.HasPrefix(line[i:], "--") || (lexer.mysql && c == '#'):
+			// line comment
+			lexer.write(line[i:])
+			i = len(line)
+
+		case strings.HasPrefix(line[i:], "/*"):
+			lexer.commentDepth = 1
+			lexer.tokenLine = lexer.line
+			lexer.write("/*")
+			i += 2
+
+		case c == '\'' || c == '"' || c == '`':
+			lexer.content()
+			lexer.quote = c
+			lexer.tokenLine = lexer.line
+			lexer.backslashEscapes = lexer.mysql && c != '`'
+			if c == '\'' && i > 0 && (line[i-1] == 'E' || line[i-1] == 'e') && (i == 1 || !isIdentifierChar(line[i-2])) {
+				// Postgres escape string constant E'...'
+				lexer.backslashEscapes = true
+			}
+			lexer.write(line[i : i+1])
+			i++
+
+		case c == '$' && !lexer.mysql && (i == 0 || !isIdentifierChar(line[i-1])) && dollarTagRegex.MatchString(line[i:]):
+			lexer.content()
+			lexer.dollarTag = dollarTagRegex.FindString(line[i:])
+			lexer.tokenLine = lexer.line
+			lexer.write(lexer.dollarTag)
+			i += len(lexer.dollarTag)
+
+		default:
+			if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+				lexer.content()
+			}
+			lexer.write(line[i : i+1])
+			i++
+		}
 	}
+}
 
-	if bufferRemaining := strings.TrimSpace(buf.String()); len(bufferRemaining) > 0 {
-		log.Printf("WARNING: Unexpected unfinished SQL query: %s. Missing a semicolon?\n", bufferRemaining)
+// content marks the buffer as holding a statement
+func (lexer *sqlLexer) content() {
+	if !lexer.hasContent {
+		lexer.hasContent = true
+		lexer.bufLine = lexer.line
 	}
+}
+
+func (lexer *sqlLexer) write(s string) {
+	lexer.buf.WriteString(s)
+}
+
+// emit ends the buffered statement, the default delimiter ";" is kept in the statement, others are
+// only known to the client
+func (lexer *sqlLexer) emit(delimiter string) {
+	if lexer.hasContent && lexer.section == "" && lexer.outsideLine == 0 {
+		lexer.outsideLine = lexer.bufLine
+	} else if lexer.hasContent && lexer.section != "" {
+		statement := strings.TrimSpace(lexer.buf.String())
+		if delimiter == ";" {
+			statement += delimiter
+		}
+		lexer.statements[lexer.section] = append(lexer.statements[lexer.section], Statement(statement))
+	}
+	lexer.buf.Reset()
+	lexer.hasContent = false
+}
 
-	if upSections == 0 && downSections == 0 {
-		log.Fatalf(`ERROR: no Up/Down annotations found, so no statements were executed.`)
+// isMySQL reports whether statements are lexed with the MySQL syntax
+func isMySQL(dialect Dialect) bool {
+	switch dialect.(type) {
+	case MySQLDialect, *MySQLDialect:
+		return true
 	}
+	return false
+}
 
-	return
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '$' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
 }
@@ -0,0 +1,130 @@
+package migrate
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSplitSQLStatements(t *testing.T) {
+	source := `
+-- +migrate Up
+-- create the table
+CREATE TABLE users (name TEXT DEFAULT 'a;b', "weird;column" TEXT); -- trailing comment
+INSERT INTO users (name) VALUES ('it''s; fine'), (E'escaped\'; quote');
+/* block comment;
+   /* nested; */ still comment; */
+CREATE FUNCTION touch() RETURNS trigger AS $body$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$body$ LANGUAGE plpgsql;
+DO $$ BEGIN PERFORM 1; END $$;
+SELECT $1::TEXT, a$b$c FROM users;
+
+-- +migrate StatementBegin
+CREATE RULE r AS ON INSERT TO users DO ALSO (NOTIFY a; NOTIFY b)
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TABLE users;
+`
+	up, noTransaction, err := splitSQLStatements("V1__users.sql", strings.NewReader(source), true, false)
+	require.NoError(t, err)
+	require.False(t, noTransaction)
+	require.Equal(t, []Statement{
+		`-- create the table
+CREATE TABLE users (name TEXT DEFAULT 'a;b', "weird;column" TEXT);`,
+		`-- trailing comment
+INSERT INTO users (name) VALUES ('it''s; fine'), (E'escaped\'; quote');`,
+		`/* block comment;
+   /* nested; */ still comment; */
+CREATE FUNCTION touch() RETURNS trigger AS $body$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$body$ LANGUAGE plpgsql;`,
+		`DO $$ BEGIN PERFORM 1; END $$;`,
+		`SELECT $1::TEXT, a$b$c FROM users;`,
+		`CREATE RULE r AS ON INSERT TO users DO ALSO (NOTIFY a; NOTIFY b)`,
+	}, up)
+
+	down, _, err := splitSQLStatements("V1__users.sql", strings.NewReader(source), false, false)
+	require.NoError(t, err)
+	require.Equal(t, []Statement{"DROP TABLE users;"}, down)
+}
+
+func TestSplitSQLStatements_MySQL(t *testing.T) {
+	source := `
+-- +migrate NoTransaction
+-- +migrate Up
+INSERT INTO users (name) VALUES ('it\'s; fine'), ("double\"; quoted"); # comment;
+DELIMITER //
+CREATE PROCEDURE count_users()
+BEGIN
+    SELECT COUNT(*) FROM users;
+END //
+DELIMITER ;
+CALL count_users();
+
+-- +migrate Down
+DELIMITER $$
+DROP PROCEDURE count_users$$
+`
+	up, noTransaction, err := splitSQLStatements("V1__users.sql", strings.NewReader(source), true, true)
+	require.NoError(t, err)
+	require.True(t, noTransaction)
+	require.Equal(t, []Statement{
+		`INSERT INTO users (name) VALUES ('it\'s; fine'), ("double\"; quoted");`,
+		`CREATE PROCEDURE count_users()
+BEGIN
+    SELECT COUNT(*) FROM users;
+END`,
+		`CALL count_users();`,
+	}, up)
+
+	down, _, err := splitSQLStatements("V1__users.sql", strings.NewReader(source), false, true)
+	require.NoError(t, err)
+	require.Equal(t, []Statement{"DROP PROCEDURE count_users"}, down)
+}
+
+func TestSplitSQLStatements_Error(t *testing.T) {
+	for _, test := range []struct {
+		source  string
+		line    int
+		message string
+	}{
+		{"CREATE TABLE users (id INT);\n", 1, "no Up/Down annotations found"},
+		{"CREATE TABLE users (id INT);\n-- +migrate Up\n", 1, "statement outside of an Up/Down section"},
+		{"-- +migrate Up\nSELECT 1;\nSELECT 'a;\n\n-- +migrate Down\n", 3, "unterminated quoted string or identifier '"},
+		{"-- +migrate Up\n/* SELECT 1;\n", 2, "unterminated block comment"},
+		{"-- +migrate Up\nDO $x$ BEGIN END $y$;\n", 2, "unterminated dollar-quoted string $x$"},
+		{"-- +migrate Up\nSELECT 1\n\n-- +migrate Down\nSELECT 2;\n", 2, `statement is not terminated by ";"`},
+		{"-- +migrate Up\nSELECT 1;\nSELECT 2\n", 3, `statement is not terminated by ";"`},
+		{"-- +migrate Up\n-- +migrate StatementBegin\nSELECT 1;\n", 2, "no matching '-- +migrate StatementEnd'"},
+		{"-- +migrate Up\n-- +migrate StatementEnd\n", 2, "no matching '-- +migrate StatementBegin'"},
+		{"-- +migrate Up\n-- +migrate Upp\n", 2, `unknown annotation "Upp"`},
+	} {
+		_, _, err := splitSQLStatements("V1__test.sql", strings.NewReader(test.source), true, false)
+		var parseErr *ParseError
+		require.True(t, errors.As(err, &parseErr), test.source)
+		require.Equal(t, "V1__test.sql", parseErr.Filename)
+		require.Equal(t, test.line, parseErr.Line, test.source)
+		require.Contains(t, parseErr.Message, test.message, test.source)
+	}
+}
+
+func TestSplitSQLStatements_TestData(t *testing.T) {
+	bz, err := os.ReadFile("test_data/V20221127104302__multi_statements.sql")
+	require.NoError(t, err)
+	up, _, err := splitSQLStatements("V20221127104302__multi_statements.sql", strings.NewReader(string(bz)), true, false)
+	require.NoError(t, err)
+	require.Len(t, up, 8)
+	down, _, err := splitSQLStatements("V20221127104302__multi_statements.sql", strings.NewReader(string(bz)), false, false)
+	require.NoError(t, err)
+	require.Len(t, down, 3)
+}
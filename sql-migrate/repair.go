@@ -0,0 +1,171 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/hawkneo/utils/log"
+)
+
+var _ Migrator = (*repairMigrator)(nil)
+
+// RepairChange is a change of a row of the schema table
+type RepairChange struct {
+	// Before is the row before the repair
+	Before *Schema
+	// After is the row after the repair, or nil if the row is deleted
+	After *Schema
+}
+
+func (change RepairChange) String() string {
+	before := fmt.Sprintf("filename: %s, hash: %s", change.Before.Filename, change.Before.Hash)
+	if change.After == nil {
+		return fmt.Sprintf("version %s, before: %s, after: deleted, no migration file", change.Before.Version, before)
+	}
+	return fmt.Sprintf("version %s, before: %s, after: filename: %s, hash: %s",
+		change.Before.Version, before, change.After.Filename, change.After.Hash,
+	)
+}
+
+type repairMigrator struct {
+	ctx     *Context
+	confirm func(changes []RepairChange) bool
+}
+
+// NewRepairMigrator creates a migrator which realigns the schema table with the migration files, e.g. after
+// the line endings or comments of an applied migration file are changed.
+//
+// The hash and filename of the applied migrations are replaced by the ones of their current files, and
+// the rows of the migrations without file are deleted. The migrations are not applied again.
+//
+// Apply logs the changes, and makes them only if confirm returns true. With Config.DryRun, the changes are
+// only logged.
+func NewRepairMigrator(ctx *Context, confirm func(changes []RepairChange) bool) (Migrator, error) {
+	if confirm == nil {
+		return nil, fmt.Errorf("confirm is not set")
+	}
+	err := loadContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repairMigrator{ctx, confirm}, nil
+}
+
+// Apply repairs the schema table while holding the migration lock
+func (migrator repairMigrator) Apply() error {
+	return withLock(migrator.ctx, func() error {
+		if err := computeStatus(migrator.ctx); err != nil {
+			return err
+		}
+		return migrator.apply()
+	})
+}
+
+func (migrator repairMigrator) apply() (err error) {
+	ctx := migrator.ctx
+	changes := migrator.changes()
+	if len(changes) == 0 {
+		ctx.Conf.Logger.Infof("%s", log.AnsiColorGreen("nothing to repair"))
+		return nil
+	}
+	for _, change := range changes {
+		ctx.Conf.Logger.Infof("repair %s", change)
+	}
+	if ctx.Conf.DryRun {
+		return nil
+	}
+	if !migrator.confirm(changes) {
+		ctx.Conf.Logger.Warnf("repair canceled")
+		return nil
+	}
+
+	tx, err := ctx.Conf.DB.BeginTx(ctx.Context, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	// the rows are changed by id, deleted rows first and renamed rows through a temporary filename,
+	// so a filename is never held by two rows, e.g. when two rows swap their filenames
+	updates := make([]RepairChange, 0)
+	for _, change := range changes {
+		if change.After != nil {
+			updates = append(updates, change)
+			continue
+		}
+		_, err = tx.ExecContext(ctx.Context, ctx.Conf.Dialect.DeleteSchemaByIDSQL(ctx.Conf.SchemaName), change.Before.ID)
+		if err != nil {
+			return fmt.Errorf("repair filename: %s, version: %s, delete schema error: %s",
+				change.Before.Filename, change.Before.Version, err,
+			)
+		}
+	}
+	for _, change := range updates {
+		if change.After.Filename == change.Before.Filename {
+			continue
+		}
+		_, err = tx.ExecContext(ctx.Context, ctx.Conf.Dialect.UpdateSchemaSQL(ctx.Conf.SchemaName),
+			fmt.Sprintf("%s.repair-%d", change.After.Filename, change.After.ID), change.After.Hash, change.After.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("repair filename: %s, version: %s, update schema error: %s",
+				change.Before.Filename, change.Before.Version, err,
+			)
+		}
+	}
+	for _, change := range updates {
+		schema := change.After
+		_, err = tx.ExecContext(ctx.Context, ctx.Conf.Dialect.UpdateSchemaSQL(ctx.Conf.SchemaName), schema.Filename, schema.Hash, schema.ID)
+		if err != nil {
+			return fmt.Errorf("repair filename: %s, version: %s, update schema error: %s",
+				change.Before.Filename, change.Before.Version, err,
+			)
+		}
+	}
+
+	ctx.Conf.Logger.Infof("%s", log.AnsiColorGreen("repair successfully"))
+	return nil
+}
+
+// changes returns the changes of the schema table, ordered by id
+func (migrator repairMigrator) changes() []RepairChange {
+	ctx := migrator.ctx
+	idToChange := make(map[uint64]RepairChange)
+	for _, ms := range ctx.ms {
+		// outdated repeatable migrations are applied again by the next migration up
+		if ms.status != StatusHashMismatch && ms.status != StatusFilenameMismatch {
+			continue
+		}
+		after := *ms.schema
+		after.Filename = ms.migration.Filename
+		after.Hash = ms.migration.fileHash
+		idToChange[ms.schema.ID] = RepairChange{Before: ms.schema, After: &after}
+	}
+
+	versions := make(map[string]bool)
+	repeatableFilenames := make(map[string]bool)
+	for _, migration := range ctx.migrations {
+		if migration.repeatable {
+			repeatableFilenames[migration.Filename] = true
+		} else {
+			versions[migration.version] = true
+		}
+	}
+
+	changes := make([]RepairChange, 0)
+	for _, schema := range ctx.schemas {
+		if change, ok := idToChange[schema.ID]; ok {
+			changes = append(changes, change)
+		} else if IsRepeatableFilename(schema.Filename) && !repeatableFilenames[schema.Filename] {
+			changes = append(changes, RepairChange{Before: schema})
+		} else if !IsRepeatableFilename(schema.Filename) && !versions[schema.Version] {
+			changes = append(changes, RepairChange{Before: schema})
+		}
+	}
+	return changes
+}
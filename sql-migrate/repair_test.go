@@ -0,0 +1,141 @@
+package migrate
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRepairMigrator(t *testing.T) {
+	db := openSQLite(t)
+	source := StringMigrationSource{Migrations: []*Migration{
+		{Filename: "V1__one.sql", Source: "-- +migrate Up\nCREATE TABLE one (id INT);\n-- +migrate Down\nDROP TABLE one;\n"},
+		{Filename: "V2__two.sql", Source: "-- +migrate Up\nCREATE TABLE two (id INT);\n-- +migrate Down\nDROP TABLE two;\n"},
+		{Filename: "V3__three.sql", Source: "-- +migrate Up\nCREATE TABLE three (id INT);\n-- +migrate Down\nDROP TABLE three;\n"},
+		{Filename: "R__view.sql", Source: "-- +migrate Up\nCREATE VIEW IF NOT EXISTS v AS SELECT 1;\n"},
+	}}
+	migrator, err := NewCreateMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+
+	_, err = NewRepairMigrator(newSQLiteContext(db, source), nil)
+	require.Error(t, err)
+
+	// line endings changed, renamed, and removed after deploy
+	source = StringMigrationSource{Migrations: []*Migration{
+		{Filename: "V1__one.sql", Source: "-- +migrate Up\r\nCREATE TABLE one (id INT);\r\n-- +migrate Down\r\nDROP TABLE one;\r\n"},
+		{Filename: "V2__create_two.sql", Source: "-- +migrate Up\nCREATE TABLE two (id INT);\n-- +migrate Down\nDROP TABLE two;\n"},
+	}}
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.ErrorContains(t, migrator.Apply(), "hash mismatch")
+
+	var changes []RepairChange
+	migrator, err = NewRepairMigrator(newSQLiteContext(db, source), func(c []RepairChange) bool {
+		changes = c
+		return false
+	})
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.Len(t, changes, 4)
+	require.Equal(t, "V1__one.sql", changes[0].After.Filename)
+	require.NotEqual(t, changes[0].Before.Hash, changes[0].After.Hash)
+	require.Equal(t, "V2__two.sql", changes[1].Before.Filename)
+	require.Equal(t, "V2__create_two.sql", changes[1].After.Filename)
+	require.Equal(t, changes[1].Before.Hash, changes[1].After.Hash)
+	require.Equal(t, "V3__three.sql", changes[2].Before.Filename)
+	require.Nil(t, changes[2].After)
+	require.Equal(t, "R__view.sql", changes[3].Before.Filename)
+	require.Nil(t, changes[3].After)
+
+	// not confirmed
+	ctx := newSQLiteContext(db, source)
+	migrator, err = NewStatusMigrator(ctx)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.Len(t, ctx.schemas, 4)
+
+	ctx = newSQLiteContext(db, source)
+	ctx.Conf.DryRun = true
+	migrator, err = NewRepairMigrator(ctx, func([]RepairChange) bool { return true })
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	migrator, err = NewValidateMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.Error(t, migrator.Apply())
+
+	migrator, err = NewRepairMigrator(newSQLiteContext(db, source), func([]RepairChange) bool { return true })
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+
+	ctx = newSQLiteContext(db, source)
+	migrator, err = NewStatusMigrator(ctx)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.Len(t, ctx.schemas, 2)
+	for _, ms := range ctx.ms {
+		require.Equal(t, StatusApplied, ms.status)
+	}
+	migrator, err = NewValidateMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+
+	// nothing to repair
+	migrator, err = NewRepairMigrator(newSQLiteContext(db, source), func([]RepairChange) bool {
+		t.Fatal("confirm without changes")
+		return false
+	})
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+}
+
+func TestRepairMigrator_SharedFilename(t *testing.T) {
+	db := openSQLite(t)
+	source := StringMigrationSource{Migrations: []*Migration{
+		{Filename: "V1__one.sql", Source: "-- +migrate Up\nSELECT 1;\n-- +migrate Down\nSELECT 1;\n"},
+		{Filename: "V2__two.sql", Source: "-- +migrate Up\nSELECT 2;\n-- +migrate Down\nSELECT 2;\n"},
+		{Filename: "V3__three.sql", Source: "-- +migrate Up\nSELECT 3;\n-- +migrate Down\nSELECT 3;\n"},
+	}}
+	migrator, err := NewCreateMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+
+	// the rows of version 1 and 2 swapped their filenames, and the file of version 3 is removed
+	for _, statement := range []string{
+		"UPDATE migration_schema SET filename = 'tmp' WHERE id = 1",
+		"UPDATE migration_schema SET filename = 'V1__one.sql' WHERE id = 2",
+		"UPDATE migration_schema SET filename = 'V2__two.sql' WHERE id = 1",
+	} {
+		_, err = db.Exec(statement)
+		require.NoError(t, err)
+	}
+	source.Migrations = source.Migrations[:2]
+
+	var changes []RepairChange
+	migrator, err = NewRepairMigrator(newSQLiteContext(db, source), func(c []RepairChange) bool {
+		changes = c
+		return true
+	})
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.Len(t, changes, 3)
+
+	ctx := newSQLiteContext(db, source)
+	migrator, err = NewStatusMigrator(ctx)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.Len(t, ctx.schemas, 2)
+	for i, schema := range ctx.schemas {
+		// the rows are kept, only their filename is changed
+		require.Equal(t, uint64(i+1), schema.ID)
+		require.Equal(t, source.Migrations[i].Filename, schema.Filename)
+	}
+	for _, ms := range ctx.ms {
+		require.Equal(t, StatusApplied, ms.status)
+	}
+}
@@ -1,20 +1,39 @@
 package migrate
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
 	"os"
 	"path"
+	"sort"
+	"strings"
 )
 
 var (
 	_ MigrationSource = (*CombinedMigrationSource)(nil)
 	_ MigrationSource = (*DirectoryMigrationSource)(nil)
 	_ MigrationSource = (*StringMigrationSource)(nil)
+	_ MigrationSource = (*GoMigrationSource)(nil)
+	_ MigrationSource = (*FSMigrationSource)(nil)
 )
 
 type MigrationSource interface {
 	LoadMigrations() (migrations []*Migration, err error)
 }
 
+// unsupportedFilenamesSource is implemented by the sources of files, which skip the files whose names
+// are not supported
+type unsupportedFilenamesSource interface {
+	// unsupportedFilenames returns the .sql files which are skipped because of their name
+	unsupportedFilenames() ([]string, error)
+}
+
+func isSkippedSQLFile(filename string) bool {
+	return strings.HasSuffix(filename, ".sql") && !IsSupportFilename(filename)
+}
+
 type CombinedMigrationSource struct {
 	Sources []MigrationSource
 }
@@ -30,6 +49,19 @@ func (source CombinedMigrationSource) LoadMigrations() (migrations []*Migration,
 	return migrations, nil
 }
 
+func (source CombinedMigrationSource) unsupportedFilenames() (filenames []string, err error) {
+	for _, ms := range source.Sources {
+		if ms, ok := ms.(unsupportedFilenamesSource); ok {
+			subFilenames, err := ms.unsupportedFilenames()
+			if err != nil {
+				return nil, err
+			}
+			filenames = append(filenames, subFilenames...)
+		}
+	}
+	return filenames, nil
+}
+
 type StringMigrationSource struct {
 	Migrations []*Migration
 }
@@ -78,3 +110,148 @@ func (source DirectoryMigrationSource) LoadMigrations() (migrations []*Migration
 
 	return migrations, nil
 }
+
+func (source DirectoryMigrationSource) unsupportedFilenames() (filenames []string, err error) {
+	entries, err := os.ReadDir(source.Directory)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && isSkippedSQLFile(entry.Name()) {
+			filenames = append(filenames, path.Join(source.Directory, entry.Name()))
+		}
+	}
+	return filenames, nil
+}
+
+// GoMigration is a migration written in Go, e.g. a backfill which needs application logic.
+// Up and Down run in the transaction of the migration, which commits if they return nil.
+type GoMigration struct {
+	// Version orders the migration with the other migrations, like the version of a SQL file
+	Version string
+	// Name is the description of the migration, it is recorded as the filename V<version>__<name>.go
+	Name string
+	Up   func(ctx context.Context, tx *sql.Tx) error
+	// Down is optional, if nil the migration is rolled back without running anything
+	Down func(ctx context.Context, tx *sql.Tx) error
+}
+
+// GoMigrationSource loads GoMigrations, combine it with the SQL files through CombinedMigrationSource
+type GoMigrationSource struct {
+	Migrations []GoMigration
+}
+
+func (source GoMigrationSource) LoadMigrations() (migrations []*Migration, err error) {
+	for _, goMigration := range source.Migrations {
+		if goMigration.Version == "" || goMigration.Name == "" {
+			return nil, fmt.Errorf("go migration version and name are required, version: %s, name: %s",
+				goMigration.Version, goMigration.Name,
+			)
+		}
+		if goMigration.Up == nil {
+			return nil, fmt.Errorf("go migration %s has no up function", goMigration.Version)
+		}
+		migrations = append(migrations, &Migration{
+			Filename: fmt.Sprintf("V%s__%s.go", goMigration.Version, goMigration.Name),
+			upFunc:   goMigration.Up,
+			downFunc: goMigration.Down,
+		})
+	}
+	return migrations, nil
+}
+
+// FSMigrationSource loads the migration files of Dir and its subdirectories in FS, e.g. an embed.FS to ship
+// the migrations inside the binary:
+//
+//	//go:embed migrations
+//	var migrations embed.FS
+//
+//	source := FSMigrationSource{FS: migrations, Dir: "migrations"}
+//
+// or the fs.FS of github.com/hawkneo/utils/io/fs to load them from a S3 bucket with NewS3DirFS. The ReadDir of
+// NewS3FS lists objects by their full key, which fs.ReadDir does not expect.
+//
+// Migrations are recorded by filename without the directory, so filenames must be unique across subdirectories.
+type FSMigrationSource struct {
+	FS fs.FS
+	// Dir is the directory of the migrations in FS, the root directory "." if not set
+	Dir string
+}
+
+func (source FSMigrationSource) LoadMigrations() (migrations []*Migration, err error) {
+	paths, err := source.paths()
+	if err != nil {
+		return nil, err
+	}
+
+	filenameToPath := make(map[string]string)
+	for _, filePath := range paths {
+		filename := path.Base(filePath)
+		if !IsSupportFilename(filename) {
+			continue
+		}
+		if duplicate, ok := filenameToPath[filename]; ok {
+			return nil, fmt.Errorf("duplicate migration filename: %s and %s", duplicate, filePath)
+		}
+		filenameToPath[filename] = filePath
+
+		bz, err := fs.ReadFile(source.FS, filePath)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, &Migration{
+			Filename: filename,
+			Source:   string(bz),
+		})
+	}
+	return migrations, nil
+}
+
+func (source FSMigrationSource) unsupportedFilenames() (filenames []string, err error) {
+	paths, err := source.paths()
+	if err != nil {
+		return nil, err
+	}
+	for _, filePath := range paths {
+		if isSkippedSQLFile(path.Base(filePath)) {
+			filenames = append(filenames, filePath)
+		}
+	}
+	return filenames, nil
+}
+
+// paths returns the sorted paths of the files in Dir and its subdirectories
+func (source FSMigrationSource) paths() ([]string, error) {
+	dir := source.Dir
+	if dir == "" {
+		dir = "."
+	}
+	paths, err := source.walk(dir)
+	if err != nil {
+		return nil, err
+	}
+	// deterministic whatever the order of ReadDir
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// walk returns the paths of the files in dir and its subdirectories
+func (source FSMigrationSource) walk(dir string) (paths []string, err error) {
+	entries, err := fs.ReadDir(source.FS, dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		entryPath := path.Join(dir, entry.Name())
+		if entry.IsDir() {
+			subPaths, err := source.walk(entryPath)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, subPaths...)
+			continue
+		}
+		paths = append(paths, entryPath)
+	}
+	return paths, nil
+}
@@ -3,10 +3,17 @@ package migrate
 import (
 	"context"
 	"database/sql"
+	"embed"
+	"errors"
+	utilsfs "github.com/hawkneo/utils/io/fs"
+	"github.com/hawkneo/utils/io/fs/s3test"
 	"github.com/hawkneo/utils/log"
+	"github.com/hawkneo/utils/math/decimal"
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/require"
+	"io/fs"
 	"testing"
+	"testing/fstest"
 )
 
 func TestDirectoryMigrationSource_LoadMigrations(t *testing.T) {
@@ -73,3 +80,177 @@ func TestDownMigrator(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, migrator.Apply())
 }
+
+func TestGoMigrationSource(t *testing.T) {
+	db := openSQLite(t)
+	var backfillErr error
+	source := CombinedMigrationSource{Sources: []MigrationSource{
+		StringMigrationSource{Migrations: []*Migration{
+			{
+				Filename: "V1__create_balances.sql",
+				Source: `
+-- +migrate Up
+CREATE TABLE balances (id INTEGER PRIMARY KEY NOT NULL, amount TEXT NOT NULL);
+INSERT INTO balances (id, amount) VALUES (1, '1.5'), (2, '2.25');
+
+-- +migrate Down
+DROP TABLE balances;
+`,
+			},
+			{
+				Filename: "V3__add_index.sql",
+				Source: `
+-- +migrate Up
+CREATE INDEX balances_wei ON balances (wei);
+
+-- +migrate Down
+DROP INDEX balances_wei;
+`,
+			},
+		}},
+		GoMigrationSource{Migrations: []GoMigration{
+			{
+				Version: "2",
+				Name:    "backfill_wei",
+				Up: func(ctx context.Context, tx *sql.Tx) error {
+					if _, err := tx.ExecContext(ctx, "ALTER TABLE balances ADD COLUMN wei TEXT"); err != nil {
+						return err
+					}
+					rows, err := tx.QueryContext(ctx, "SELECT id, amount FROM balances")
+					if err != nil {
+						return err
+					}
+					amounts := make(map[int64]string)
+					for rows.Next() {
+						var (
+							id     int64
+							amount string
+						)
+						if err := rows.Scan(&id, &amount); err != nil {
+							return err
+						}
+						amounts[id] = amount
+					}
+					rows.Close()
+					for id, amount := range amounts {
+						wei, err := decimal.NewFromString(amount)
+						if err != nil {
+							return err
+						}
+						_, err = tx.ExecContext(ctx, "UPDATE balances SET wei = ? WHERE id = ?", wei.RescaleDown(18).BigInt().String(), id)
+						if err != nil {
+							return err
+						}
+					}
+					return backfillErr
+				},
+				Down: func(ctx context.Context, tx *sql.Tx) error {
+					_, err := tx.ExecContext(ctx, "ALTER TABLE balances DROP COLUMN wei")
+					return err
+				},
+			},
+		}},
+	}}
+
+	migrator, err := NewCreateMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+
+	// a failed go migration is rolled back with its transaction
+	backfillErr = errors.New("backfill failed")
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.ErrorIs(t, migrator.Apply(), backfillErr)
+	require.Equal(t, map[string]string{"id": "INTEGER", "amount": "TEXT"}, sqliteColumns(t, db, "balances"))
+
+	backfillErr = nil
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	var wei string
+	require.NoError(t, db.QueryRow("SELECT wei FROM balances WHERE id = 2").Scan(&wei))
+	require.Equal(t, "2250000000000000000", wei)
+
+	ctx := newSQLiteContext(db, source)
+	migrator, err = NewStatusMigrator(ctx)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	filenames := make([]string, 0)
+	for _, ms := range ctx.ms {
+		require.Equal(t, StatusApplied, ms.status)
+		filenames = append(filenames, ms.schema.Filename)
+	}
+	require.Equal(t, []string{"V1__create_balances.sql", "V2__backfill_wei.go", "V3__add_index.sql"}, filenames)
+
+	migrator, err = NewDownMigrator(newSQLiteContext(db, source), "1")
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.Equal(t, map[string]string{"id": "INTEGER", "amount": "TEXT"}, sqliteColumns(t, db, "balances"))
+
+	_, err = GoMigrationSource{Migrations: []GoMigration{{Version: "1", Name: "no_up"}}}.LoadMigrations()
+	require.Error(t, err)
+}
+
+//go:embed test_data/sqlite
+var sqliteMigrations embed.FS
+
+func TestFSMigrationSource_LoadMigrations(t *testing.T) {
+	files := fstest.MapFS{
+		"migrations/V2__b.sql":          {Data: []byte("b")},
+		"migrations/V1__a.sql":          {Data: []byte("a")},
+		"migrations/2023/V3__c.sql":     {Data: []byte("c")},
+		"migrations/2023/06/V4__d.sql":  {Data: []byte("d")},
+		"migrations/views/R__views.sql": {Data: []byte("r")},
+		"migrations/README.md":          {Data: []byte("readme")},
+		"migrations_old/V0__old.sql":    {Data: []byte("old")},
+	}
+	expected := []string{"V4__d.sql", "V3__c.sql", "V1__a.sql", "V2__b.sql", "R__views.sql"}
+
+	objects := make(map[string][]byte)
+	for filePath, file := range files {
+		objects[filePath] = file.Data
+	}
+	server := s3test.NewServer("migrations", objects)
+	defer server.Close()
+	s3FS := utilsfs.NewS3DirFS(server.Client(), "migrations")
+
+	for name, fsys := range map[string]fs.FS{"map": files, "s3": s3FS} {
+		migrations, err := FSMigrationSource{FS: fsys, Dir: "migrations"}.LoadMigrations()
+		require.NoError(t, err, name)
+		filenames := make([]string, 0)
+		for _, migration := range migrations {
+			filenames = append(filenames, migration.Filename)
+		}
+		require.Equal(t, expected, filenames, name)
+		require.Equal(t, "d", migrations[0].Source, name)
+	}
+
+	// the root directory by default
+	for name, fsys := range map[string]fs.FS{"map": files, "s3": s3FS} {
+		for _, dir := range []string{"", "."} {
+			migrations, err := FSMigrationSource{FS: fsys, Dir: dir}.LoadMigrations()
+			require.NoError(t, err, name)
+			require.Len(t, migrations, 6, name)
+		}
+	}
+
+	files["migrations/2023/V1__a.sql"] = &fstest.MapFile{Data: []byte("a")}
+	_, err := FSMigrationSource{FS: files, Dir: "migrations"}.LoadMigrations()
+	require.ErrorContains(t, err, "duplicate migration filename")
+
+	_, err = FSMigrationSource{FS: files, Dir: "missing"}.LoadMigrations()
+	require.Error(t, err)
+}
+
+func TestFSMigrationSource_Embed(t *testing.T) {
+	db := openSQLite(t)
+	source := FSMigrationSource{FS: sqliteMigrations, Dir: "test_data/sqlite"}
+
+	migrator, err := NewCreateMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	require.NotEmpty(t, sqliteColumns(t, db, "users"))
+}
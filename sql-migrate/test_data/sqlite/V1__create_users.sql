@@ -0,0 +1,11 @@
+-- +migrate Up
+CREATE TABLE users
+(
+    id INTEGER PRIMARY KEY NOT NULL ,
+    name TEXT NOT NULL ,
+    age TEXT
+);
+INSERT INTO users (id, name, age) VALUES (1, 'alice', '30');
+
+-- +migrate Down
+DROP TABLE users;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE users ADD COLUMN email TEXT;
+CREATE UNIQUE INDEX users_email ON users (email);
+
+-- +migrate Down
+DROP INDEX users_email;
+ALTER TABLE users DROP COLUMN email;
@@ -0,0 +1,26 @@
+-- +migrate Up
+-- SQLite cannot change the type of a column, rebuild the table
+CREATE TABLE new_users
+(
+    id INTEGER PRIMARY KEY NOT NULL ,
+    name TEXT NOT NULL ,
+    age INTEGER NOT NULL DEFAULT 0 ,
+    email TEXT
+);
+INSERT INTO new_users (id, name, age, email) SELECT id, name, CAST(age AS INTEGER), email FROM users;
+DROP TABLE users;
+ALTER TABLE new_users RENAME TO users;
+CREATE UNIQUE INDEX users_email ON users (email);
+
+-- +migrate Down
+CREATE TABLE old_users
+(
+    id INTEGER PRIMARY KEY NOT NULL ,
+    name TEXT NOT NULL ,
+    age TEXT ,
+    email TEXT
+);
+INSERT INTO old_users (id, name, age, email) SELECT id, name, CAST(age AS TEXT), email FROM users;
+DROP TABLE users;
+ALTER TABLE old_users RENAME TO users;
+CREATE UNIQUE INDEX users_email ON users (email);
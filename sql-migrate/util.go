@@ -1,16 +1,24 @@
 package migrate
 
 import (
+	"fmt"
 	"regexp"
-	"strconv"
 	"strings"
 )
 
 var regex = regexp.MustCompile("^V.+__.*?\\.sql$")
-var digitRegex = regexp.MustCompile("^[1-9][0-9]*$")
+var repeatableRegex = regexp.MustCompile("^R__.+\\.sql$")
+var numericRegex = regexp.MustCompile("^[0-9]+$")
+var versionRegex = regexp.MustCompile("^[^._]+([._][^._]+)*$")
 
 func IsSupportFilename(filename string) bool {
-	return regex.MatchString(filename)
+	return regex.MatchString(filename) || repeatableRegex.MatchString(filename)
+}
+
+// IsRepeatableFilename reports whether filename is a repeatable migration R__<description>.sql,
+// which has no version and is applied again whenever its content changes
+func IsRepeatableFilename(filename string) bool {
+	return repeatableRegex.MatchString(filename)
 }
 
 func SplitFilename(filename string) (version string) {
@@ -18,17 +26,21 @@ func SplitFilename(filename string) (version string) {
 	return filename[1:index]
 }
 
+// CompareVersion reports whether version vi sorts before version vj.
+//
+// Versions are compared like Flyway does, segment by segment, split on "." and "_":
+// numeric segments are compared numerically, e.g. 2.9 < 2.10 and 9.1 < 10, whatever their length,
+// e.g. timestamps like 20221125112000. Other segments are compared as strings, after the numeric ones.
+// If all segments of a version prefix the other, it sorts first, e.g. 1 < 1.0 < 1.1.
 func CompareVersion(vi, vj string) bool {
-	if digitRegex.MatchString(vi) && digitRegex.MatchString(vj) {
-		vii, err := strconv.ParseUint(vi, 10, 64)
-		if err != nil {
-			return compareVersionString(vi, vj)
-		}
-		vji, err := strconv.ParseUint(vj, 10, 64)
-		if err != nil {
-			return compareVersionString(vi, vj)
+	si, sj := splitVersion(vi), splitVersion(vj)
+	for k := 0; k < len(si) && k < len(sj); k++ {
+		if c := compareVersionSegment(si[k], sj[k]); c != 0 {
+			return c < 0
 		}
-		return vii < vji
+	}
+	if len(si) != len(sj) {
+		return len(si) < len(sj)
 	}
 	return compareVersionString(vi, vj)
 }
@@ -36,3 +48,60 @@ func CompareVersion(vi, vj string) bool {
 func compareVersionString(vi, vj string) bool {
 	return vi < vj
 }
+
+func splitVersion(version string) []string {
+	return strings.FieldsFunc(version, func(r rune) bool {
+		return r == '.' || r == '_'
+	})
+}
+
+func compareVersionSegment(a, b string) int {
+	aNumeric, bNumeric := numericRegex.MatchString(a), numericRegex.MatchString(b)
+	switch {
+	case aNumeric && bNumeric:
+		a, b = trimLeadingZeros(a), trimLeadingZeros(b)
+		if len(a) != len(b) {
+			if len(a) < len(b) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	case aNumeric:
+		return -1
+	case bNumeric:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func trimLeadingZeros(segment string) string {
+	trimmed := strings.TrimLeft(segment, "0")
+	if trimmed == "" {
+		return "0"
+	}
+	return trimmed
+}
+
+// normalizeVersion returns the canonical form of a version, versions of the same canonical form
+// are ambiguous, e.g. 1, 1.0 and 01
+func normalizeVersion(version string) string {
+	segments := splitVersion(version)
+	for i, segment := range segments {
+		if numericRegex.MatchString(segment) {
+			segments[i] = trimLeadingZeros(segment)
+		}
+	}
+	for len(segments) > 1 && segments[len(segments)-1] == "0" {
+		segments = segments[:len(segments)-1]
+	}
+	return strings.Join(segments, ".")
+}
+
+// ValidateVersion returns an error if the version is empty or has an empty segment, e.g. 1..2
+func ValidateVersion(version string) error {
+	if !versionRegex.MatchString(version) {
+		return fmt.Errorf("invalid version %q: empty segment", version)
+	}
+	return nil
+}
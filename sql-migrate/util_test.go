@@ -8,8 +8,63 @@ import (
 func TestIsSupportFilename(t *testing.T) {
 	require.False(t, IsSupportFilename("V__init_sql.sql"))
 	require.True(t, IsSupportFilename("V1__init_sql.sql"))
+	require.True(t, IsSupportFilename("R__views.sql"))
+	require.False(t, IsSupportFilename("R__.sql"))
+}
+
+func TestIsRepeatableFilename(t *testing.T) {
+	require.True(t, IsRepeatableFilename("R__views.sql"))
+	require.False(t, IsRepeatableFilename("V1__views.sql"))
 }
 
 func TestSplitFilename(t *testing.T) {
 	require.Equal(t, "12345", SplitFilename("V12345__init_sql.sql"))
 }
+
+func TestCompareVersion(t *testing.T) {
+	ordered := []string{
+		"1", "1.0", "1.1", "1.2", "1.10", "1_11", "2", "2.1", "2.9", "2.10", "9.1", "10",
+		"10.a", "10.b", "20221125112000", "20221125112000.1", "20221125191249",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			require.Equal(t, i < j, CompareVersion(ordered[i], ordered[j]), "%s < %s", ordered[i], ordered[j])
+		}
+	}
+	require.True(t, CompareVersion("01", "1"))
+	require.False(t, CompareVersion("1", "01"))
+}
+
+func TestNormalizeVersion(t *testing.T) {
+	require.Equal(t, "1", normalizeVersion("1.0"))
+	require.Equal(t, "1", normalizeVersion("01.0.0"))
+	require.Equal(t, "1.2", normalizeVersion("1_02"))
+	require.Equal(t, "0", normalizeVersion("0.0"))
+	require.Equal(t, "1.0.a", normalizeVersion("1.0.a"))
+}
+
+func TestValidateVersion(t *testing.T) {
+	require.NoError(t, ValidateVersion("1.2_3"))
+	require.NoError(t, ValidateVersion("20221125112000"))
+	for _, version := range []string{"", ".1", "1.", "1..2", "1._2"} {
+		require.Error(t, ValidateVersion(version), version)
+	}
+}
+
+func TestValidateVersions(t *testing.T) {
+	load := func(filenames ...string) error {
+		migrations := make([]*Migration, len(filenames))
+		for i, filename := range filenames {
+			migrations[i] = &Migration{
+				Filename:   filename,
+				version:    SplitFilename(filename),
+				repeatable: IsRepeatableFilename(filename),
+			}
+		}
+		return validateVersions(migrations)
+	}
+	require.NoError(t, load("V1__a.sql", "V1.1__b.sql", "V2__c.sql", "R__views.sql", "R__functions.sql"))
+	require.ErrorContains(t, load("V1__a.sql", "V1.0__b.sql"), "ambiguous versions: 1 (V1__a.sql) and 1.0 (V1.0__b.sql)")
+	require.ErrorContains(t, load("V1__a.sql", "V1__b.sql"), "duplicate version: 1")
+	require.ErrorContains(t, load("V1..2__a.sql"), "empty segment")
+}
@@ -0,0 +1,169 @@
+package migrate
+
+import (
+	"fmt"
+	"github.com/hawkneo/utils/log"
+	"strings"
+)
+
+var _ Migrator = (*validateMigrator)(nil)
+
+// ValidationError is the error of the migrations which do not pass the validation, the problems are
+// logged by the migrator
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%d migration problem(s) found", len(e.Problems))
+}
+
+type validateMigrator struct {
+	ctx *Context
+}
+
+// NewValidateMigrator creates a migrator which checks the migrations without applying them, e.g. in CI.
+//
+// Without Config.DB, the migration files are checked alone: the filenames match the pattern, the versions
+// are valid and unambiguous, and the statements of each file can be split into an Up and a Down section.
+// With Config.DB, the migration files are also checked against the schema table, a file whose hash or
+// filename differs from the applied one, or which is out of order, is a problem, and so is a missing schema
+// table. The schema table is read without the migration lock, validate writes nothing and does not wait for a
+// running migration, whose migration in progress may be reported as partially applied.
+//
+// Apply logs every problem and returns a *ValidationError with all of them.
+func NewValidateMigrator(ctx *Context) (Migrator, error) {
+	err := loadContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return validateMigrator{ctx}, nil
+}
+
+func (migrator validateMigrator) Apply() error {
+	ctx := migrator.ctx
+	problems, err := migrator.offlineProblems()
+	if err != nil {
+		return err
+	}
+
+	if ctx.Conf.DB != nil {
+		exists, err := schemaTableExists(ctx)
+		if err != nil {
+			return err
+		}
+		if exists {
+			if err := computeStatus(ctx); err != nil {
+				return err
+			}
+			problems = append(problems, migrator.statusProblems()...)
+		} else {
+			problems = append(problems, fmt.Sprintf("schema table %s does not exist, run create first", ctx.Conf.SchemaName))
+		}
+	}
+
+	if len(problems) == 0 {
+		ctx.Conf.Logger.Infof("%s", log.AnsiColorGreen("validate successfully"))
+		return nil
+	}
+	for _, problem := range problems {
+		ctx.Conf.Logger.Errorf("%s", problem)
+	}
+	return &ValidationError{Problems: problems}
+}
+
+// schemaTableExists reports whether the schema table exists, it is assumed to exist with the dialects
+// it does not know, whose status read fails otherwise
+func schemaTableExists(ctx *Context) (bool, error) {
+	conf := ctx.Conf
+	var query string
+	switch conf.Dialect.(type) {
+	case SQLiteDialect, *SQLiteDialect:
+		query = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	case MySQLDialect, *MySQLDialect:
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+	case PostgresDialect, *PostgresDialect:
+		query = "SELECT COUNT(*) FROM (SELECT to_regclass($1) AS t) r WHERE r.t IS NOT NULL"
+	default:
+		return true, nil
+	}
+	var count int
+	if err := conf.DB.QueryRowContext(ctx.Context, query, conf.SchemaName).Scan(&count); err != nil {
+		return false, fmt.Errorf("read schema table %s error: %w", conf.SchemaName, err)
+	}
+	return count > 0, nil
+}
+
+// offlineProblems returns the problems of the migration files
+func (migrator validateMigrator) offlineProblems() (problems []string, err error) {
+	ctx := migrator.ctx
+	if source, ok := ctx.Conf.MigrationSource.(unsupportedFilenamesSource); ok {
+		filenames, err := source.unsupportedFilenames()
+		if err != nil {
+			return nil, err
+		}
+		for _, filename := range filenames {
+			problems = append(problems, fmt.Sprintf("filename: %s, does not match V<version>__<description>.sql "+
+				"or R__<description>.sql, skipped", filename,
+			))
+		}
+	}
+
+	for _, err := range versionErrors(ctx.migrations) {
+		problems = append(problems, err.Error())
+	}
+
+	mysql := isMySQL(ctx.Conf.Dialect)
+	for _, migration := range ctx.migrations {
+		if migration.isGo() {
+			continue
+		}
+		if !IsSupportFilename(migration.Filename) {
+			problems = append(problems, fmt.Sprintf("filename: %s, does not match V<version>__<description>.sql "+
+				"or R__<description>.sql", migration.Filename,
+			))
+			continue
+		}
+		lexer, err := lexSQL(migration.Filename, strings.NewReader(migration.Source), mysql)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if !lexer.sections["Up"] {
+			problems = append(problems, fmt.Sprintf("filename: %s, no '-- +migrate Up' section", migration.Filename))
+		}
+		// repeatable migrations are never rolled back
+		if !lexer.sections["Down"] && !migration.repeatable {
+			problems = append(problems, fmt.Sprintf("filename: %s, no '-- +migrate Down' section", migration.Filename))
+		}
+	}
+	return problems, nil
+}
+
+// statusProblems returns the problems of the migration files against the schema table
+func (migrator validateMigrator) statusProblems() (problems []string) {
+	ctx := migrator.ctx
+	for _, ms := range ctx.ms {
+		switch ms.status {
+		case StatusHashMismatch:
+			problems = append(problems, fmt.Sprintf("filename: %s, version %s, hash mismatch, expected: %s, actual: %s",
+				ms.migration.Filename, ms.migration.version, ms.schema.Hash, ms.migration.fileHash,
+			))
+		case StatusFilenameMismatch:
+			problems = append(problems, fmt.Sprintf("filename: %s, version %s, filename mismatch, expected: %s, actual: %s",
+				ms.migration.Filename, ms.migration.version, ms.schema.Filename, ms.migration.Filename,
+			))
+		case StatusPartiallyApplied:
+			problems = append(problems, fmt.Sprintf("filename: %s, version %s, partially applied",
+				ms.migration.Filename, ms.migration.version,
+			))
+		case StatusOutOfOrder:
+			if !ctx.Conf.MigrateOutOfOrder {
+				problems = append(problems, fmt.Sprintf("filename: %s, version %s, out of order",
+					ms.migration.Filename, ms.migration.version,
+				))
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,106 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestValidateMigrator_Offline(t *testing.T) {
+	// without DB
+	ctx := newSQLiteContext(nil, DirectoryMigrationSource{Directory: "./test_data/sqlite"})
+	migrator, err := NewValidateMigrator(ctx)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+
+	fsys := fstest.MapFS{
+		"V1__ok.sql":            {Data: []byte("-- +migrate Up\nCREATE TABLE t (id INT);\n-- +migrate Down\nDROP TABLE t;\n")},
+		"V1.0__ambiguous.sql":   {Data: []byte("-- +migrate Up\nSELECT 1;\n-- +migrate Down\nSELECT 1;\n")},
+		"V2__no_down.sql":       {Data: []byte("-- +migrate Up\nSELECT 1;\n")},
+		"V3__unterminated.sql":  {Data: []byte("-- +migrate Up\nSELECT 1\n-- +migrate Down\nSELECT 1;\n")},
+		"V4__begin.sql":         {Data: []byte("-- +migrate Up\n-- +migrate StatementBegin\nSELECT 1;\n-- +migrate Down\n")},
+		"V5__no_up.sql":         {Data: []byte("-- +migrate Down\nSELECT 1;\n")},
+		"R__view.sql":           {Data: []byte("-- +migrate Up\nSELECT 1;\n")},
+		"v6_wrong_pattern.sql":  {Data: []byte("-- +migrate Up\nSELECT 1;\n")},
+		"README.md":             {Data: []byte("not a migration")},
+		"V7__unterminated2.sql": {Data: []byte("-- +migrate Up\nSELECT 'a;\n-- +migrate Down\n")},
+	}
+	ctx.Conf.MigrationSource = FSMigrationSource{FS: fsys}
+	migrator, err = NewValidateMigrator(ctx)
+	require.NoError(t, err)
+	err = migrator.Apply()
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Equal(t, []string{
+		"filename: v6_wrong_pattern.sql, does not match V<version>__<description>.sql or R__<description>.sql, skipped",
+		"ambiguous versions: 1 (V1__ok.sql) and 1.0 (V1.0__ambiguous.sql)",
+		"filename: V2__no_down.sql, no '-- +migrate Down' section",
+		"V3__unterminated.sql:2: statement is not terminated by \";\"",
+		"V4__begin.sql:2: saw '-- +migrate StatementBegin' with no matching '-- +migrate StatementEnd'",
+		"filename: V5__no_up.sql, no '-- +migrate Up' section",
+		"V7__unterminated2.sql:2: unterminated quoted string or identifier '",
+	}, validationErr.Problems)
+}
+
+func TestValidateMigrator_DB(t *testing.T) {
+	db := openSQLite(t)
+	source := StringMigrationSource{Migrations: []*Migration{
+		{Filename: "V1__one.sql", Source: "-- +migrate Up\nSELECT 1;\n-- +migrate Down\nSELECT 1;\n"},
+		{Filename: "V3__three.sql", Source: "-- +migrate Up\nSELECT 3;\n-- +migrate Down\nSELECT 3;\n"},
+	}}
+	migrator, err := NewCreateMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+	migrator, err = NewUpMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+
+	migrator, err = NewValidateMigrator(newSQLiteContext(db, source))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Apply())
+
+	// edited after deploy, renamed, and added out of order
+	source = StringMigrationSource{Migrations: []*Migration{
+		{Filename: "V1__one.sql", Source: "-- +migrate Up\nSELECT 1; -- edited\n-- +migrate Down\nSELECT 1;\n"},
+		{Filename: "V2__two.sql", Source: "-- +migrate Up\nSELECT 2;\n-- +migrate Down\nSELECT 2;\n"},
+		{Filename: "V3__renamed.sql", Source: "-- +migrate Up\nSELECT 3;\n-- +migrate Down\nSELECT 3;\n"},
+	}}
+	ctx := newSQLiteContext(db, source)
+	migrator, err = NewValidateMigrator(ctx)
+	require.NoError(t, err)
+	err = migrator.Apply()
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Len(t, validationErr.Problems, 3)
+	require.Contains(t, validationErr.Problems[0], "filename: V1__one.sql, version 1, hash mismatch")
+	require.Equal(t, "filename: V2__two.sql, version 2, out of order", validationErr.Problems[1])
+	require.Equal(t, "filename: V3__renamed.sql, version 3, filename mismatch, expected: V3__three.sql, actual: V3__renamed.sql",
+		validationErr.Problems[2])
+
+	ctx.Conf.MigrateOutOfOrder = true
+	err = migrator.Apply()
+	require.True(t, errors.As(err, &validationErr))
+	require.Len(t, validationErr.Problems, 2)
+
+	// the status printing setting of the context is kept
+	ctx.Conf.printStatus = true
+	_ = migrator.Apply()
+	require.True(t, ctx.Conf.printStatus)
+
+	// the schema table is read while another migrator holds the lock
+	unlock, err := SQLiteDialect{}.Lock(context.TODO(), db, "migration_schema", "pod-1", time.Second)
+	require.NoError(t, err)
+	ctx.Conf.LockTimeout = 10 * time.Millisecond
+	require.True(t, errors.As(migrator.Apply(), &validationErr))
+	require.Len(t, validationErr.Problems, 2)
+	require.NoError(t, unlock())
+
+	// a missing schema table is a problem
+	migrator, err = NewValidateMigrator(newSQLiteContext(openSQLite(t), source))
+	require.NoError(t, err)
+	require.True(t, errors.As(migrator.Apply(), &validationErr))
+	require.Equal(t, []string{"schema table migration_schema does not exist, run create first"}, validationErr.Problems)
+}